@@ -3,10 +3,12 @@ package main
 import (
 	"log"
 	"os"
+	"time"
 
 	"github.com/aalsa/management_dashboard/internal/db"
 	"github.com/aalsa/management_dashboard/internal/handlers"
 	"github.com/aalsa/management_dashboard/internal/middleware"
+	"github.com/aalsa/management_dashboard/internal/models"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -21,13 +23,17 @@ func main() {
 	}
 	defer database.Close()
 
+	if err := handlers.EnsureAdmin(database); err != nil {
+		log.Fatal("Failed to create the admin account:", err)
+	}
+
 	r := gin.Default()
 
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"http://localhost:5173"},
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE"},
 		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		ExposeHeaders:    []string{"Content-Length", "Content-Disposition"},
 		AllowCredentials: true,
 	}))
 
@@ -35,7 +41,23 @@ func main() {
 	projectHandler := handlers.NewProjectHandler(database)
 	taskHandler := handlers.NewTaskHandler(database)
 	timeLogHandler := handlers.NewTimeLogHandler(database)
+	timerHandler := handlers.NewTimerHandler(database)
+	timesheetHandler := handlers.NewTimesheetHandler(database)
+	leaveHandler := handlers.NewLeaveHandler(database)
+	holidayHandler := handlers.NewHolidayHandler(database)
+	commentHandler := handlers.NewCommentHandler(database)
+	notificationHandler := handlers.NewNotificationHandler(database)
+	workflowHandler := handlers.NewWorkflowHandler(database)
+	sprintHandler := handlers.NewSprintHandler(database)
+	reportHandler := handlers.NewReportHandler(database)
+	analyticsHandler := handlers.NewAnalyticsHandler(database)
+	departmentHandler := handlers.NewDepartmentHandler(database)
+	teamHandler := handlers.NewTeamHandler(database)
+	skillHandler := handlers.NewSkillHandler(database)
+	clientHandler := handlers.NewClientHandler(database)
+	invoiceHandler := handlers.NewInvoiceHandler(database)
 	authHandler := handlers.NewAuthHandler(database)
+	userHandler := handlers.NewUserHandler(database)
 
 	r.GET("/api/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{"status": "ok"})
@@ -45,39 +67,204 @@ func main() {
 	{
 		auth.POST("/register", authHandler.Register)
 		auth.POST("/login", authHandler.Login)
-		auth.GET("/me", middleware.AuthMiddleware(), authHandler.GetMe)
+		auth.POST("/refresh", authHandler.Refresh)
+		auth.GET("/me", middleware.AuthMiddleware(database), authHandler.GetMe)
+		auth.POST("/logout", middleware.AuthMiddleware(database), authHandler.Logout)
+		auth.GET("/sessions", middleware.AuthMiddleware(database), authHandler.GetSessions)
+		auth.DELETE("/sessions/:id", middleware.AuthMiddleware(database), authHandler.RevokeSession)
 	}
 
 	api := r.Group("/api")
-	api.Use(middleware.AuthMiddleware())
+	api.Use(middleware.AuthMiddleware(database))
 	{
 		api.GET("/employees", employeeHandler.GetAll)
-		api.POST("/employees", employeeHandler.Create)
 		api.GET("/employees/:id", employeeHandler.GetByID)
-		api.PUT("/employees/:id", employeeHandler.Update)
-		api.DELETE("/employees/:id", employeeHandler.Delete)
 		api.GET("/employees/:id/hours", timeLogHandler.GetEmployeeHours)
+		api.GET("/employees/:id/projects", projectHandler.GetEmployeeProjects)
+		api.GET("/employees/:id/reports", employeeHandler.GetReports)
+		api.GET("/employees/:id/skills", employeeHandler.GetSkills)
+		api.GET("/employees/:id/leave-balances", leaveHandler.GetBalances)
+		api.GET("/org-chart", employeeHandler.GetOrgChart)
+
+		api.GET("/departments", departmentHandler.GetAll)
+		api.GET("/departments/:id", departmentHandler.GetByID)
+		api.GET("/departments/:id/skills-matrix", departmentHandler.GetSkillsMatrix)
+
+		api.GET("/holiday-calendars", holidayHandler.GetAll)
+		api.GET("/holiday-calendars/:id", holidayHandler.GetByID)
+		api.GET("/teams", teamHandler.GetAll)
+		api.GET("/teams/:id", teamHandler.GetByID)
+
+		api.GET("/skills", skillHandler.GetAll)
+		api.GET("/skills/:id", skillHandler.GetByID)
 
 		api.GET("/projects", projectHandler.GetAll)
-		api.POST("/projects", projectHandler.Create)
 		api.GET("/projects/:id", projectHandler.GetByID)
-		api.PUT("/projects/:id", projectHandler.Update)
-		api.DELETE("/projects/:id", projectHandler.Delete)
+		api.GET("/projects/:id/members", projectHandler.GetMembers)
+		api.GET("/projects/:id/critical-path", taskHandler.GetCriticalPath)
+		api.GET("/projects/:id/workflow", workflowHandler.Get)
+		api.GET("/projects/:id/sprints", sprintHandler.GetByProject)
+
+		api.GET("/sprints/:id", sprintHandler.GetByID)
+		api.GET("/sprints/:id/burndown", sprintHandler.GetBurndown)
+
+		api.GET("/reports/estimates", reportHandler.GetEstimates)
+
+		api.GET("/analytics/velocity", analyticsHandler.GetVelocity)
+		api.GET("/analytics/throughput", analyticsHandler.GetThroughput)
+		api.GET("/analytics/completion-rate", analyticsHandler.GetCompletionRate)
+		api.GET("/analytics/cycle-time", analyticsHandler.GetCycleTime)
+		api.GET("/analytics/utilization", analyticsHandler.GetUtilization)
 
 		api.GET("/tasks", taskHandler.GetAll)
-		api.POST("/tasks", taskHandler.Create)
 		api.GET("/tasks/:id", taskHandler.GetByID)
-		api.PUT("/tasks/:id", taskHandler.Update)
-		api.DELETE("/tasks/:id", taskHandler.Delete)
 		api.GET("/tasks/:id/hours", timeLogHandler.GetTaskHours)
+		api.GET("/tasks/:id/dependencies", taskHandler.GetDependencies)
+		api.GET("/tasks/:id/subtasks", taskHandler.GetSubtasks)
+		api.GET("/tasks/:id/comments", commentHandler.GetAll)
+		api.GET("/tasks/:id/history", taskHandler.GetHistory)
+		api.GET("/tasks/:id/skills", taskHandler.GetSkills)
+		api.GET("/tasks/:id/suggested-assignees", taskHandler.GetSuggestedAssignees)
 
 		api.GET("/time-logs", timeLogHandler.GetAll)
-		api.POST("/time-logs", timeLogHandler.Create)
 		api.GET("/time-logs/:id", timeLogHandler.GetByID)
-		api.PUT("/time-logs/:id", timeLogHandler.Update)
-		api.DELETE("/time-logs/:id", timeLogHandler.Delete)
+
+		api.GET("/timesheets", timesheetHandler.GetAll)
+		api.GET("/timesheets/:id", timesheetHandler.GetByID)
+
+		api.GET("/leave-requests", leaveHandler.GetAll)
+		api.GET("/leave-requests/:id", leaveHandler.GetByID)
+
+		api.GET("/me/tasks", taskHandler.GetMine)
+		api.GET("/me/time-logs", timeLogHandler.GetMine)
+		api.GET("/me/projects", projectHandler.GetMine)
+
+		api.GET("/notifications", notificationHandler.GetAll)
+		api.POST("/notifications/read-all", notificationHandler.MarkAllRead)
+		api.POST("/notifications/:id/read", notificationHandler.MarkRead)
+	}
+
+	members := api.Group("", middleware.RequireRole(models.RoleAdmin, models.RoleManager, models.RoleMember))
+	{
+		members.PUT("/tasks/:id", taskHandler.Update)
+		members.POST("/tasks/:id/dependencies", taskHandler.AddDependency)
+		members.DELETE("/tasks/:id/dependencies/:blockerId", taskHandler.RemoveDependency)
+		members.PUT("/tasks/:id/skills/:skillId", taskHandler.SetSkill)
+		members.DELETE("/tasks/:id/skills/:skillId", taskHandler.RemoveSkill)
+		members.POST("/tasks/:id/comments", commentHandler.Create)
+		members.PUT("/comments/:id", commentHandler.Update)
+		members.DELETE("/comments/:id", commentHandler.Delete)
+
+		members.POST("/time-logs", timeLogHandler.Create)
+		members.PUT("/time-logs/:id", timeLogHandler.Update)
+		members.DELETE("/time-logs/:id", timeLogHandler.Delete)
+
+		members.POST("/timers/start", timerHandler.Start)
+		members.POST("/timers/stop", timerHandler.Stop)
+		members.GET("/timers/current", timerHandler.GetCurrent)
+
+		members.PUT("/employees/:id/skills/:skillId", employeeHandler.SetSkill)
+		members.DELETE("/employees/:id/skills/:skillId", employeeHandler.RemoveSkill)
+
+		members.POST("/timesheets", timesheetHandler.Create)
+		members.POST("/timesheets/:id/submit", timesheetHandler.Submit)
+
+		members.POST("/leave-requests", leaveHandler.Create)
+		members.POST("/leave-requests/:id/cancel", leaveHandler.Cancel)
 	}
 
+	managers := api.Group("", middleware.RequireRole(models.RoleAdmin, models.RoleManager))
+	{
+		managers.POST("/employees", employeeHandler.Create)
+		managers.POST("/employees/import", employeeHandler.Import)
+		managers.PUT("/employees/:id", employeeHandler.Update)
+		managers.DELETE("/employees/:id", employeeHandler.Delete)
+		managers.GET("/employees/:id/rates", employeeHandler.GetRates)
+		managers.POST("/employees/:id/rates", employeeHandler.AddRate)
+		managers.DELETE("/employees/:id/rates/:rateId", employeeHandler.DeleteRate)
+		managers.PUT("/employees/:id/leave-balances", leaveHandler.SetBalance)
+
+		managers.POST("/projects", projectHandler.Create)
+		managers.POST("/projects/import", projectHandler.Import)
+		managers.PUT("/projects/:id", projectHandler.Update)
+		managers.GET("/projects/:id/budget", projectHandler.GetBudget)
+		managers.POST("/projects/:id/members", projectHandler.AddMember)
+		managers.DELETE("/projects/:id/members/:employeeId", projectHandler.RemoveMember)
+		managers.PUT("/projects/:id/workflow", workflowHandler.Update)
+		managers.DELETE("/projects/:id/workflow", workflowHandler.Reset)
+		managers.POST("/projects/:id/sprints", sprintHandler.Create)
+
+		managers.PUT("/sprints/:id", sprintHandler.Update)
+		managers.DELETE("/sprints/:id", sprintHandler.Delete)
+		managers.POST("/sprints/:id/tasks", sprintHandler.AddTask)
+		managers.DELETE("/sprints/:id/tasks/:taskId", sprintHandler.RemoveTask)
+		managers.POST("/sprints/:id/start", sprintHandler.Start)
+		managers.POST("/sprints/:id/close", sprintHandler.Close)
+
+		managers.POST("/teams", teamHandler.Create)
+		managers.PUT("/teams/:id", teamHandler.Update)
+		managers.DELETE("/teams/:id", teamHandler.Delete)
+		managers.POST("/teams/:id/members", teamHandler.AddMember)
+		managers.DELETE("/teams/:id/members/:employeeId", teamHandler.RemoveMember)
+
+		managers.POST("/skills", skillHandler.Create)
+		managers.PUT("/skills/:id", skillHandler.Update)
+
+		managers.GET("/clients", clientHandler.GetAll)
+		managers.GET("/clients/:id", clientHandler.GetByID)
+		managers.POST("/clients", clientHandler.Create)
+		managers.PUT("/clients/:id", clientHandler.Update)
+
+		managers.GET("/invoices", invoiceHandler.GetAll)
+		managers.POST("/invoices", invoiceHandler.Create)
+		managers.GET("/invoices/:id", invoiceHandler.GetByID)
+		managers.GET("/invoices/:id/pdf", invoiceHandler.GetPDF)
+		managers.POST("/invoices/:id/issue", invoiceHandler.Issue)
+		managers.POST("/invoices/:id/pay", invoiceHandler.MarkPaid)
+		managers.POST("/invoices/:id/void", invoiceHandler.Void)
+		managers.DELETE("/invoices/:id", invoiceHandler.Delete)
+
+		managers.POST("/tasks", taskHandler.Create)
+		managers.POST("/tasks/import", taskHandler.Import)
+		managers.DELETE("/tasks/:id", taskHandler.Delete)
+
+		managers.POST("/timesheets/:id/approve", timesheetHandler.Approve)
+		managers.POST("/timesheets/:id/reject", timesheetHandler.Reject)
+
+		managers.POST("/leave-requests/:id/approve", leaveHandler.Approve)
+		managers.POST("/leave-requests/:id/reject", leaveHandler.Reject)
+	}
+
+	admins := api.Group("", middleware.RequireRole(models.RoleAdmin))
+	{
+		admins.DELETE("/projects/:id", projectHandler.Delete)
+		admins.DELETE("/clients/:id", clientHandler.Delete)
+
+		admins.POST("/departments", departmentHandler.Create)
+		admins.PUT("/departments/:id", departmentHandler.Update)
+		admins.DELETE("/departments/:id", departmentHandler.Delete)
+
+		admins.POST("/holiday-calendars", holidayHandler.Create)
+		admins.PUT("/holiday-calendars/:id", holidayHandler.Update)
+		admins.DELETE("/holiday-calendars/:id", holidayHandler.Delete)
+		admins.POST("/holiday-calendars/:id/holidays", holidayHandler.AddHoliday)
+		admins.DELETE("/holiday-calendars/:id/holidays/:holidayId", holidayHandler.RemoveHoliday)
+
+		admins.DELETE("/skills/:id", skillHandler.Delete)
+
+		admins.GET("/users", userHandler.GetAll)
+		admins.PUT("/users/:id/role", userHandler.UpdateRole)
+		admins.PUT("/users/:id/employee", userHandler.LinkEmployee)
+	}
+
+	go func() {
+		for range time.Tick(time.Minute) {
+			if err := timerHandler.StopExpired(); err != nil {
+				log.Println("Failed to auto-stop timers:", err)
+			}
+		}
+	}()
+
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8080"
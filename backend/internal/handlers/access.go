@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/aalsa/management_dashboard/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+// canAccessEmployee reports whether the caller may manage records owned by the
+// given employee: admins always, managers for anyone who reports to them or
+// works in a department they belong to or head, and members only for
+// themselves.
+func canAccessEmployee(c *gin.Context, db sqlx.Queryer, employeeID string) bool {
+	switch c.GetString("role") {
+	case models.RoleAdmin:
+		return true
+	case models.RoleManager:
+		if isManagerOf(db, c.GetString("employeeID"), employeeID) {
+			return true
+		}
+		var departmentID string
+		query := `SELECT department_id FROM employees WHERE id = $1`
+		if err := sqlx.Get(db, &departmentID, query, employeeID); err != nil {
+			return false
+		}
+		return canAccessDepartment(c, db, departmentID)
+	case models.RoleMember:
+		return employeeID != "" && employeeID == c.GetString("employeeID")
+	}
+	return false
+}
+
+// canAccessDepartment reports whether the caller may manage records in the
+// department: admins always, managers for their own department and the ones
+// they head.
+func canAccessDepartment(c *gin.Context, db sqlx.Queryer, departmentID string) bool {
+	switch c.GetString("role") {
+	case models.RoleAdmin:
+		return true
+	case models.RoleManager:
+		var allowed bool
+		query := `SELECT EXISTS (SELECT 1 FROM employees WHERE id = $1 AND department_id::text = $2)
+		          OR EXISTS (SELECT 1 FROM departments WHERE head_id = $1 AND id::text = $2)`
+		if err := sqlx.Get(db, &allowed, query, c.GetString("employeeID"), departmentID); err != nil {
+			return false
+		}
+		return allowed
+	}
+	return false
+}
+
+// isManagerOf reports whether employeeID reports to managerID, directly or
+// through other managers.
+func isManagerOf(db sqlx.Queryer, managerID, employeeID string) bool {
+	if managerID == "" {
+		return false
+	}
+	var found bool
+	query := `WITH RECURSIVE chain AS (
+	              SELECT manager_id FROM employees WHERE id = $1
+	              UNION
+	              SELECT e.manager_id FROM employees e JOIN chain ON e.id = chain.manager_id
+	          )
+	          SELECT EXISTS (SELECT 1 FROM chain WHERE manager_id = $2)`
+	if err := sqlx.Get(db, &found, query, employeeID, managerID); err != nil {
+		return false
+	}
+	return found
+}
+
+// canReview reports whether the caller may approve or reject the employee's
+// timesheets and leave: admins and the employee's managers, but never the
+// employee themselves. Employees with a manager are reviewed by someone in
+// their management chain; the department rule only covers employees without
+// one.
+func canReview(c *gin.Context, db sqlx.Queryer, employeeID string) bool {
+	role := c.GetString("role")
+	if role != models.RoleAdmin && role != models.RoleManager {
+		return false
+	}
+	if employeeID == c.GetString("employeeID") {
+		return false
+	}
+	if role == models.RoleAdmin {
+		return true
+	}
+
+	var managerID *string
+	if err := sqlx.Get(db, &managerID, `SELECT manager_id FROM employees WHERE id = $1`, employeeID); err != nil {
+		return false
+	}
+	if managerID != nil {
+		return isManagerOf(db, c.GetString("employeeID"), employeeID)
+	}
+	return canAccessEmployee(c, db, employeeID)
+}
+
+// managedEmployees selects the employees a manager, given as the only
+// placeholder, may access under canAccessEmployee's rules: everyone who
+// reports to them and everyone in a department they belong to or head.
+const managedEmployees = `(WITH RECURSIVE me AS (SELECT ?::uuid AS id),
+	reports AS (
+		SELECT e.id FROM employees e JOIN me ON e.manager_id = me.id
+		UNION
+		SELECT e.id FROM employees e JOIN reports r ON e.manager_id = r.id
+	)
+	SELECT id FROM reports
+	UNION
+	SELECT e.id FROM employees e, me
+	WHERE e.department_id IN (SELECT department_id FROM employees WHERE id = me.id
+	                          UNION SELECT id FROM departments WHERE head_id = me.id))`
+
+// scopeToEmployees limits a list to the records of employees the caller may
+// see: everyone for admins, the employees they may access for managers and
+// only their own for other roles. column names the list's employee column.
+func scopeToEmployees(c *gin.Context, q *listQuery, column string) {
+	employeeID := c.GetString("employeeID")
+	switch {
+	case c.GetString("role") == models.RoleAdmin:
+	case employeeID == "":
+		q.conditions = append(q.conditions, "false")
+	case c.GetString("role") == models.RoleManager:
+		q.where(column+" IN "+managedEmployees, employeeID)
+	default:
+		q.where(column+" = ?", employeeID)
+	}
+}
+
+// canAccessTask applies the employee rules to the task's assignee. Unassigned
+// tasks can only be managed by admins and managers.
+func canAccessTask(c *gin.Context, db sqlx.Queryer, assignedTo *string) bool {
+	if assignedTo == nil {
+		role := c.GetString("role")
+		return role == models.RoleAdmin || role == models.RoleManager
+	}
+	return canAccessEmployee(c, db, *assignedTo)
+}
+
+// currentEmployee returns the employee linked to the caller's account, or
+// writes a 404 when the account has no employee record.
+func currentEmployee(c *gin.Context) (string, bool) {
+	employeeID := c.GetString("employeeID")
+	if employeeID == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No employee is linked to this account"})
+		return "", false
+	}
+	return employeeID, true
+}
@@ -0,0 +1,416 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type AnalyticsHandler struct {
+	db *sqlx.DB
+}
+
+func NewAnalyticsHandler(db *sqlx.DB) *AnalyticsHandler {
+	return &AnalyticsHandler{db: db}
+}
+
+// analyticsGroup maps a ?group_by value to the SQL expressions that identify
+// and label a group, plus any joins they need. Queries alias tasks as t,
+// projects as p, the assignee as e and their department as d.
+type analyticsGroup struct {
+	id   string
+	name string
+	join string
+}
+
+// teamJoins adds one row per team of employee e, so an employee in several
+// teams counts towards each of them.
+const teamJoins = `
+	LEFT JOIN team_members tm ON tm.employee_id = e.id
+	LEFT JOIN teams tt ON tt.id = tm.team_id`
+
+var analyticsGroups = map[string]analyticsGroup{
+	"project":    {id: "t.project_id::text", name: "p.name"},
+	"assignee":   {id: "t.assigned_to::text", name: "e.full_name"},
+	"department": {id: "e.department_id::text", name: "d.name"},
+	"team":       {id: "tt.id::text", name: "tt.name", join: teamJoins},
+	"priority":   {id: "t.priority", name: "t.priority"},
+}
+
+var throughputIntervals = map[string]bool{"day": true, "week": true, "month": true}
+
+const analyticsJoins = `
+	FROM tasks t
+	LEFT JOIN projects p ON p.id = t.project_id
+	LEFT JOIN employees e ON e.id = t.assigned_to
+	LEFT JOIN departments d ON d.id = e.department_id`
+
+// analyticsQuery holds the parameters shared by the analytics endpoints: a
+// date window (?from, ?to, inclusive), a grouping dimension (?group_by) and
+// an optional ?project_id filter. args always starts with from and to as $1
+// and $2, so window conditions can be repeated in a query.
+type analyticsQuery struct {
+	From    time.Time
+	To      time.Time
+	GroupBy string
+	group   analyticsGroup
+	project string
+	filter  string
+	args    []interface{}
+}
+
+func parseAnalyticsQuery(c *gin.Context, groups map[string]analyticsGroup, defaultGroup string) (*analyticsQuery, error) {
+	to := time.Now().UTC().Truncate(24 * time.Hour)
+	if value := c.Query("to"); value != "" {
+		t, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return nil, fmt.Errorf("to must be a date in YYYY-MM-DD format")
+		}
+		to = t
+	}
+
+	from := to.AddDate(0, 0, -7*12)
+	if value := c.Query("from"); value != "" {
+		t, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return nil, fmt.Errorf("from must be a date in YYYY-MM-DD format")
+		}
+		from = t
+	}
+	if from.After(to) {
+		return nil, fmt.Errorf("from must not be after to")
+	}
+
+	groupBy := c.DefaultQuery("group_by", defaultGroup)
+	group, ok := groups[groupBy]
+	if !ok {
+		return nil, fmt.Errorf("cannot group by %s", groupBy)
+	}
+
+	q := &analyticsQuery{
+		From:    from,
+		To:      to,
+		GroupBy: groupBy,
+		group:   group,
+		args:    []interface{}{from.Format("2006-01-02"), to.AddDate(0, 0, 1).Format("2006-01-02")},
+	}
+
+	if projectID := c.Query("project_id"); projectID != "" {
+		if _, err := uuid.Parse(projectID); err != nil {
+			return nil, fmt.Errorf("project_id must be a valid id")
+		}
+		q.project = projectID
+		q.args = append(q.args, projectID)
+		q.filter = fmt.Sprintf(" AND t.project_id = $%d", len(q.args))
+	}
+
+	return q, nil
+}
+
+// joins returns the FROM clause for queries grouped by q's group.
+func (q *analyticsQuery) joins() string {
+	return analyticsJoins + q.group.join
+}
+
+// within returns a condition selecting rows whose column falls in the window.
+func (q *analyticsQuery) within(column string) string {
+	return fmt.Sprintf("%s >= $1 AND %s < $2", column, column)
+}
+
+// weeks is the number of weeks the window spans, rounded up.
+func (q *analyticsQuery) weeks() int {
+	days := int(q.To.Sub(q.From).Hours()/24) + 1
+	return (days + 6) / 7
+}
+
+type AnalyticsResponse struct {
+	From     string      `json:"from"`
+	To       string      `json:"to"`
+	GroupBy  string      `json:"group_by"`
+	Interval string      `json:"interval,omitempty"`
+	Data     interface{} `json:"data"`
+	Summary  interface{} `json:"summary,omitempty"`
+}
+
+func (q *analyticsQuery) response(data, summary interface{}) AnalyticsResponse {
+	return AnalyticsResponse{
+		From:    q.From.Format("2006-01-02"),
+		To:      q.To.Format("2006-01-02"),
+		GroupBy: q.GroupBy,
+		Data:    data,
+		Summary: summary,
+	}
+}
+
+type VelocityWeek struct {
+	Week            time.Time `db:"week" json:"week"`
+	GroupID         *string   `db:"group_id" json:"group_id"`
+	GroupName       *string   `db:"group_name" json:"group_name"`
+	CompletedTasks  int       `db:"completed_tasks" json:"completed_tasks"`
+	CompletedPoints int       `db:"completed_points" json:"completed_points"`
+}
+
+type VelocitySummary struct {
+	GroupID          *string `db:"group_id" json:"group_id"`
+	GroupName        *string `db:"group_name" json:"group_name"`
+	CompletedTasks   int     `db:"completed_tasks" json:"completed_tasks"`
+	CompletedPoints  int     `db:"completed_points" json:"completed_points"`
+	AvgTasksPerWeek  float64 `db:"avg_tasks_per_week" json:"avg_tasks_per_week"`
+	AvgPointsPerWeek float64 `db:"avg_points_per_week" json:"avg_points_per_week"`
+}
+
+// GetVelocity reports completed tasks and story points per week for each
+// group, with the weekly average over the whole window.
+func (h *AnalyticsHandler) GetVelocity(c *gin.Context) {
+	q, err := parseAnalyticsQuery(c, analyticsGroups, "project")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	weeks := []VelocityWeek{}
+	query := fmt.Sprintf(`
+	SELECT date_trunc('week', t.completed_at) AS week, %s AS group_id, %s AS group_name,
+	       COUNT(*) AS completed_tasks, COALESCE(SUM(t.story_points), 0) AS completed_points
+	%s
+	WHERE %s%s
+	GROUP BY 1, 2, 3
+	ORDER BY 1, 3`, q.group.id, q.group.name, q.joins(), q.within("t.completed_at"), q.filter)
+	if err := h.db.Select(&weeks, query, q.args...); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	summary := []VelocitySummary{}
+	args := append(append([]interface{}{}, q.args...), q.weeks())
+	query = fmt.Sprintf(`
+	SELECT %s AS group_id, %s AS group_name,
+	       COUNT(*) AS completed_tasks, COALESCE(SUM(t.story_points), 0) AS completed_points,
+	       ROUND(COUNT(*)::numeric / $%d, 2) AS avg_tasks_per_week,
+	       ROUND(COALESCE(SUM(t.story_points), 0)::numeric / $%d, 2) AS avg_points_per_week
+	%s
+	WHERE %s%s
+	GROUP BY 1, 2
+	ORDER BY 2`, q.group.id, q.group.name, len(args), len(args), q.joins(), q.within("t.completed_at"), q.filter)
+	if err := h.db.Select(&summary, query, args...); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	respondReport(c, q.response(weeks, summary), csvSection{"weeks", weeks}, csvSection{"summary", summary})
+}
+
+type ThroughputPeriod struct {
+	Period    time.Time `db:"period" json:"period"`
+	GroupID   *string   `db:"group_id" json:"group_id"`
+	GroupName *string   `db:"group_name" json:"group_name"`
+	Created   int       `db:"created" json:"created"`
+	Completed int       `db:"completed" json:"completed"`
+}
+
+// GetThroughput counts tasks created and completed per ?interval (day, week
+// or month; default week) for each group.
+func (h *AnalyticsHandler) GetThroughput(c *gin.Context) {
+	q, err := parseAnalyticsQuery(c, analyticsGroups, "project")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	interval := c.DefaultQuery("interval", "week")
+	if !throughputIntervals[interval] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "interval must be one of day, week, month"})
+		return
+	}
+
+	periods := []ThroughputPeriod{}
+	query := fmt.Sprintf(`
+	SELECT period, group_id, group_name,
+	       COUNT(*) FILTER (WHERE kind = 'created') AS created,
+	       COUNT(*) FILTER (WHERE kind = 'completed') AS completed
+	FROM (
+		SELECT date_trunc('%[1]s', t.created_at) AS period, 'created' AS kind, %[2]s AS group_id, %[3]s AS group_name
+		%[4]s
+		WHERE %[5]s%[7]s
+		UNION ALL
+		SELECT date_trunc('%[1]s', t.completed_at), 'completed', %[2]s, %[3]s
+		%[4]s
+		WHERE %[6]s%[7]s
+	) events
+	GROUP BY 1, 2, 3
+	ORDER BY 1, 3`, interval, q.group.id, q.group.name, q.joins(),
+		q.within("t.created_at"), q.within("t.completed_at"), q.filter)
+	if err := h.db.Select(&periods, query, q.args...); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := q.response(periods, nil)
+	response.Interval = interval
+	respondReport(c, response, csvSection{"periods", periods})
+}
+
+type CompletionRate struct {
+	GroupID        *string  `db:"group_id" json:"group_id"`
+	GroupName      *string  `db:"group_name" json:"group_name"`
+	Due            int      `db:"due" json:"due"`
+	Completed      int      `db:"completed" json:"completed"`
+	OnTime         int      `db:"on_time" json:"on_time"`
+	Overdue        int      `db:"overdue" json:"overdue"`
+	CompletionRate *float64 `db:"completion_rate" json:"completion_rate"`
+	OnTimeRate     *float64 `db:"on_time_rate" json:"on_time_rate"`
+}
+
+// GetCompletionRate looks at tasks due within the window and reports, per
+// group, how many were completed, how many on or before their due date and
+// how many are still open past it. Rates are percentages of the due tasks.
+func (h *AnalyticsHandler) GetCompletionRate(c *gin.Context) {
+	q, err := parseAnalyticsQuery(c, analyticsGroups, "project")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rates := []CompletionRate{}
+	query := fmt.Sprintf(`
+	SELECT group_id, group_name, due, completed, on_time, overdue,
+	       ROUND(100.0 * completed / NULLIF(due, 0), 1) AS completion_rate,
+	       ROUND(100.0 * on_time / NULLIF(due, 0), 1) AS on_time_rate
+	FROM (
+		SELECT %s AS group_id, %s AS group_name,
+		       COUNT(*) AS due,
+		       COUNT(*) FILTER (WHERE t.completed_at IS NOT NULL) AS completed,
+		       COUNT(*) FILTER (WHERE t.completed_at::date <= t.due_date) AS on_time,
+		       COUNT(*) FILTER (WHERE t.completed_at IS NULL AND t.due_date < CURRENT_DATE) AS overdue
+		%s
+		WHERE %s%s
+		GROUP BY 1, 2
+	) groups
+	ORDER BY group_name`, q.group.id, q.group.name, q.joins(), q.within("t.due_date"), q.filter)
+	if err := h.db.Select(&rates, query, q.args...); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	respondReport(c, q.response(rates, nil), csvSection{"groups", rates})
+}
+
+type CycleTimeStats struct {
+	GroupID   *string  `db:"group_id" json:"group_id"`
+	GroupName *string  `db:"group_name" json:"group_name"`
+	Completed int      `db:"completed" json:"completed"`
+	Started   int      `db:"started" json:"started"`
+	LeadP50   *float64 `db:"lead_p50" json:"lead_time_p50"`
+	LeadP85   *float64 `db:"lead_p85" json:"lead_time_p85"`
+	LeadP95   *float64 `db:"lead_p95" json:"lead_time_p95"`
+	CycleP50  *float64 `db:"cycle_p50" json:"cycle_time_p50"`
+	CycleP85  *float64 `db:"cycle_p85" json:"cycle_time_p85"`
+	CycleP95  *float64 `db:"cycle_p95" json:"cycle_time_p95"`
+}
+
+type WorkInProgress struct {
+	TaskID     string    `db:"task_id" json:"task_id"`
+	Title      string    `db:"title" json:"title"`
+	ProjectID  string    `db:"project_id" json:"project_id"`
+	AssignedTo *string   `db:"assigned_to" json:"assigned_to"`
+	Status     string    `db:"status" json:"status"`
+	StartedAt  time.Time `db:"started_at" json:"started_at"`
+	AgeDays    float64   `db:"age_days" json:"age_days"`
+	Stuck      bool      `db:"-" json:"stuck"`
+}
+
+type CycleTimeResponse struct {
+	AnalyticsResponse
+	InProgress []WorkInProgress `json:"in_progress"`
+}
+
+// taskStarted joins the first time each task t moved to in_progress.
+const taskStarted = `
+	LEFT JOIN LATERAL (
+		SELECT MIN(ev.created_at) AS started_at
+		FROM task_events ev
+		WHERE ev.task_id = t.id AND ev.field = 'status' AND ev.new_value = 'in_progress'
+	) s ON true`
+
+func (q *analyticsQuery) cycleTimeQuery(group analyticsGroup) string {
+	return fmt.Sprintf(`
+	WITH durations AS (
+		SELECT %s AS group_id, %s AS group_name,
+		       EXTRACT(EPOCH FROM t.completed_at - t.created_at) / 86400 AS lead_days,
+		       EXTRACT(EPOCH FROM t.completed_at - s.started_at) / 86400 AS cycle_days
+		%s%s
+		WHERE %s%s
+	)
+	SELECT group_id, group_name, COUNT(*) AS completed, COUNT(cycle_days) AS started,
+	       ROUND(percentile_cont(0.50) WITHIN GROUP (ORDER BY lead_days)::numeric, 1) AS lead_p50,
+	       ROUND(percentile_cont(0.85) WITHIN GROUP (ORDER BY lead_days)::numeric, 1) AS lead_p85,
+	       ROUND(percentile_cont(0.95) WITHIN GROUP (ORDER BY lead_days)::numeric, 1) AS lead_p95,
+	       ROUND(percentile_cont(0.50) WITHIN GROUP (ORDER BY cycle_days)::numeric, 1) AS cycle_p50,
+	       ROUND(percentile_cont(0.85) WITHIN GROUP (ORDER BY cycle_days)::numeric, 1) AS cycle_p85,
+	       ROUND(percentile_cont(0.95) WITHIN GROUP (ORDER BY cycle_days)::numeric, 1) AS cycle_p95
+	FROM durations
+	GROUP BY 1, 2
+	ORDER BY 2`, group.id, group.name, analyticsJoins+group.join, taskStarted, q.within("t.completed_at"), q.filter)
+}
+
+// GetCycleTime reports lead time (created to completed) and cycle time (first
+// in_progress to completed) percentiles in days for tasks completed in the
+// window, per group and overall. in_progress lists started, unfinished tasks;
+// those older than the overall p85 cycle time are flagged as stuck.
+func (h *AnalyticsHandler) GetCycleTime(c *gin.Context) {
+	q, err := parseAnalyticsQuery(c, analyticsGroups, "project")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	groups := []CycleTimeStats{}
+	if err := h.db.Select(&groups, q.cycleTimeQuery(q.group), q.args...); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	overall := []CycleTimeStats{}
+	if err := h.db.Select(&overall, q.cycleTimeQuery(analyticsGroup{id: "NULL::text", name: "NULL::text"}), q.args...); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	summary := CycleTimeStats{}
+	if len(overall) > 0 {
+		summary = overall[0]
+	}
+
+	// Open work is not limited to the window, only to the project.
+	args := []interface{}{}
+	filter := ""
+	if q.project != "" {
+		args = append(args, q.project)
+		filter = " AND t.project_id = $1"
+	}
+
+	inProgress := []WorkInProgress{}
+	query := fmt.Sprintf(`
+	SELECT t.id AS task_id, t.title, t.project_id, t.assigned_to, t.status, s.started_at,
+	       ROUND((EXTRACT(EPOCH FROM CURRENT_TIMESTAMP - s.started_at) / 86400)::numeric, 1) AS age_days
+	%s%s
+	WHERE t.completed_at IS NULL AND s.started_at IS NOT NULL%s
+	ORDER BY s.started_at`, analyticsJoins, taskStarted, filter)
+	if err := h.db.Select(&inProgress, query, args...); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	for i := range inProgress {
+		inProgress[i].Stuck = summary.CycleP85 != nil && inProgress[i].AgeDays > *summary.CycleP85
+	}
+
+	response := CycleTimeResponse{
+		AnalyticsResponse: q.response(groups, summary),
+		InProgress:        inProgress,
+	}
+	respondReport(c, response, csvSection{"groups", groups},
+		csvSection{"summary", []CycleTimeStats{summary}}, csvSection{"in_progress", inProgress})
+}
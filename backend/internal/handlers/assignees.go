@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/aalsa/management_dashboard/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// Weights of the suggestion score. Each part is a 0-100 value: how well the
+// employee's skills cover the task's requirements, how much of a week's
+// capacity their open work leaves free, and how far below full utilization
+// they logged recently.
+const (
+	skillMatchWeight   = 0.6
+	availabilityWeight = 0.2
+	idleWeight         = 0.2
+)
+
+type SuggestedAssignee struct {
+	EmployeeID        string   `db:"employee_id" json:"employee_id"`
+	FullName          string   `db:"full_name" json:"full_name"`
+	DepartmentID      string   `db:"department_id" json:"department_id"`
+	ProjectMember     bool     `db:"project_member" json:"project_member"`
+	SkillsMet         int      `db:"skills_met" json:"skills_met"`
+	MeetsRequirements bool     `db:"meets_requirements" json:"meets_requirements"`
+	SkillMatch        float64  `db:"skill_match" json:"skill_match"`
+	OpenTasks         int      `db:"open_tasks" json:"open_tasks"`
+	OpenHours         float64  `db:"open_hours" json:"open_hours"`
+	Utilization       *float64 `db:"utilization" json:"utilization"`
+	WeekCapacity      float64  `db:"week_capacity" json:"-"`
+	Score             float64  `json:"score"`
+}
+
+type SuggestedAssigneesResponse struct {
+	TaskID         string              `json:"task_id"`
+	RequiredSkills []models.TaskSkill  `json:"required_skills"`
+	Weeks          int                 `json:"weeks"`
+	Suggestions    []SuggestedAssignee `json:"suggestions"`
+}
+
+// GetSuggestedAssignees ranks active employees for the task. skill_match
+// averages, over the required skills, the employee's proficiency as a share
+// of the required level (capped at 100%); tasks without requirements match
+// everyone fully. open_hours is the remaining estimate of their other open
+// tasks and utilization the hours logged over the last ?weeks (default 4)
+// against capacity. ?members_only=true limits the list to project members and
+// ?limit= (default 10) caps it. Approved leave and holidays reduce both
+// capacities by a fifth of a week per weekday off.
+func (h *TaskHandler) GetSuggestedAssignees(c *gin.Context) {
+	id := c.Param("id")
+
+	weeks := 4
+	if value := c.Query("weeks"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > 52 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "weeks must be between 1 and 52"})
+			return
+		}
+		weeks = n
+	}
+
+	limit := 10
+	if value := c.Query("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > 100 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 100"})
+			return
+		}
+		limit = n
+	}
+
+	var task models.Task
+	if err := h.db.Get(&task, `SELECT * FROM tasks WHERE id = $1`, id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+
+	required, err := requiredSkills(h.db, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	filter := ""
+	if c.Query("members_only") == "true" {
+		filter = " AND project_member"
+	}
+
+	suggestions := []SuggestedAssignee{}
+	query := fmt.Sprintf(`
+	WITH matches AS (
+		SELECT e.id AS employee_id,
+		       COUNT(*) FILTER (WHERE es.proficiency >= ts.min_proficiency) AS skills_met,
+		       AVG(LEAST(COALESCE(es.proficiency, 0)::numeric / ts.min_proficiency, 1)) AS coverage
+		FROM employees e
+		JOIN task_skills ts ON ts.task_id = $1
+		LEFT JOIN employee_skills es ON es.employee_id = e.id AND es.skill_id = ts.skill_id
+		GROUP BY 1
+	),
+	open_work AS (
+		SELECT t.assigned_to AS employee_id, COUNT(*) AS open_tasks,
+		       SUM(GREATEST(COALESCE(t.estimated_hours, 0)
+		           - COALESCE((SELECT SUM(l.hours) FROM time_logs l WHERE l.task_id = t.id), 0), 0)) AS open_hours
+		FROM tasks t
+		WHERE t.completed_at IS NULL AND t.assigned_to IS NOT NULL AND t.id <> $1
+		GROUP BY 1
+	),
+	logged AS (
+		SELECT employee_id, SUM(hours) AS hours
+		FROM time_logs
+		WHERE log_date >= CURRENT_DATE - 7 * $2::int AND log_date < CURRENT_DATE
+		GROUP BY 1
+	),
+	off AS (
+		SELECT employee_id,
+		       COUNT(*) FILTER (WHERE day < CURRENT_DATE) AS past_days,
+		       LEAST(COUNT(*) FILTER (WHERE day >= date_trunc('week', CURRENT_DATE)), 5) AS week_days
+		FROM %s
+		WHERE day >= CURRENT_DATE - 7 * $2::int AND day < date_trunc('week', CURRENT_DATE) + interval '7 days'
+		GROUP BY 1
+	),
+	candidates AS (
+		SELECT e.id AS employee_id, e.full_name, e.department_id,
+		       e.weekly_capacity_hours * (1 - COALESCE(off.week_days, 0) / 5.0) AS week_capacity,
+		       EXISTS (SELECT 1 FROM project_assignments pa
+		               WHERE pa.project_id = $3 AND pa.employee_id = e.id) AS project_member,
+		       COALESCE(m.skills_met, 0) AS skills_met,
+		       COALESCE(m.skills_met, 0) = (SELECT COUNT(*) FROM task_skills WHERE task_id = $1) AS meets_requirements,
+		       ROUND(100 * COALESCE(m.coverage, 1), 1) AS skill_match,
+		       COALESCE(o.open_tasks, 0) AS open_tasks,
+		       COALESCE(o.open_hours, 0) AS open_hours,
+		       ROUND(100 * COALESCE(l.hours, 0) / NULLIF(e.weekly_capacity_hours * ($2::int - COALESCE(off.past_days, 0) / 5.0), 0), 1) AS utilization
+		FROM employees e
+		LEFT JOIN matches m ON m.employee_id = e.id
+		LEFT JOIN open_work o ON o.employee_id = e.id
+		LEFT JOIN logged l ON l.employee_id = e.id
+		LEFT JOIN off ON off.employee_id = e.id
+		WHERE e.status = 'active'
+	)
+	SELECT employee_id, full_name, department_id, project_member, skills_met, meets_requirements,
+	       skill_match, open_tasks, open_hours, utilization, week_capacity
+	FROM candidates
+	WHERE true%s`, daysOffTable, filter)
+	if err := h.db.Select(&suggestions, query, id, weeks, task.ProjectID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	for i := range suggestions {
+		s := &suggestions[i]
+		s.Score = suggestionScore(s.SkillMatch, s.OpenHours, s.WeekCapacity, s.Utilization)
+	}
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		if suggestions[i].Score != suggestions[j].Score {
+			return suggestions[i].Score > suggestions[j].Score
+		}
+		return suggestions[i].FullName < suggestions[j].FullName
+	})
+	if len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+
+	response := SuggestedAssigneesResponse{
+		TaskID:         id,
+		RequiredSkills: required,
+		Weeks:          weeks,
+		Suggestions:    suggestions,
+	}
+	respondReport(c, response, csvSection{"suggestions", suggestions}, csvSection{"required_skills", required})
+}
+
+// suggestionScore weighs the skill match with the share of a week's capacity
+// the open work leaves free and the idleness over the lookback. Employees
+// without capacity this week, such as those on leave all week, have no
+// availability; without a utilization figure they count as fully utilized.
+func suggestionScore(skillMatch, openHours, weekCapacity float64, utilization *float64) float64 {
+	availability := 0.0
+	if weekCapacity > 0 {
+		availability = math.Max(1-openHours/weekCapacity, 0)
+	}
+
+	idle := 0.0
+	if utilization != nil {
+		idle = 100 - math.Min(*utilization, 100)
+	}
+
+	score := skillMatchWeight*skillMatch + availabilityWeight*100*availability + idleWeight*idle
+	return math.Round(score*10) / 10
+}
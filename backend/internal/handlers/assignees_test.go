@@ -0,0 +1,34 @@
+package handlers
+
+import "testing"
+
+func TestSuggestionScore(t *testing.T) {
+	pct := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name         string
+		skillMatch   float64
+		openHours    float64
+		weekCapacity float64
+		utilization  *float64
+		want         float64
+	}{
+		{"idle and free", 100, 0, 40, pct(0), 100},
+		{"half booked", 100, 20, 40, pct(50), 80},
+		{"overbooked", 50, 60, 40, pct(100), 30},
+		{"over utilized", 100, 0, 40, pct(150), 80},
+		{"no utilization", 100, 0, 40, nil, 80},
+		{"zero capacity", 100, 0, 0, nil, 60},
+		{"zero capacity with open work", 80, 10, 0, pct(20), 64},
+		{"rounded", 33.3, 0, 30, pct(33.3), 53.3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := suggestionScore(tt.skillMatch, tt.openHours, tt.weekCapacity, tt.utilization)
+			if got != tt.want {
+				t.Errorf("suggestionScore(%v, %v, %v) = %v, want %v",
+					tt.skillMatch, tt.openHours, tt.weekCapacity, got, tt.want)
+			}
+		})
+	}
+}
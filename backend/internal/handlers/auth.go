@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"os"
 	"time"
@@ -10,6 +11,7 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -32,8 +34,9 @@ type LoginRequest struct {
 }
 
 type AuthResponse struct {
-	Token string       `json:"token"`
-	User  *models.User `json:"user"`
+	Token        string       `json:"token"`
+	RefreshToken string       `json:"refresh_token"`
+	User         *models.User `json:"user"`
 }
 
 func (h *AuthHandler) Register(c *gin.Context) {
@@ -55,20 +58,71 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		PasswordHash: string(hashedPassword),
 	}
 
-	query := `INSERT INTO users (id, email, password_hash) VALUES ($1, $2, $3) RETURNING created_at`
-	err = h.db.QueryRow(query, user.ID, user.Email, user.PasswordHash).Scan(&user.CreatedAt)
-	if err != nil {
+	// Sign-ups start as members; the admin comes from EnsureAdmin.
+	err = insertUser(h.db, &user, models.RoleMember)
+	if isUniqueViolation(err) {
 		c.JSON(http.StatusConflict, gin.H{"error": "Email already exists"})
 		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
 	}
 
-	token, err := generateToken(user.ID)
+	response, err := h.startSession(c, user)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
 		return
 	}
 
-	c.JSON(http.StatusCreated, AuthResponse{Token: token, User: &user})
+	c.JSON(http.StatusCreated, response)
+}
+
+// EnsureAdmin creates the admin account named by ADMIN_EMAIL and
+// ADMIN_PASSWORD unless an account with that email exists. Without
+// ADMIN_EMAIL it does nothing.
+func EnsureAdmin(db *sqlx.DB) error {
+	email := os.Getenv("ADMIN_EMAIL")
+	if email == "" {
+		return nil
+	}
+	password := os.Getenv("ADMIN_PASSWORD")
+	if len(password) < 6 {
+		return errors.New("ADMIN_PASSWORD must be at least 6 characters when ADMIN_EMAIL is set")
+	}
+
+	var exists bool
+	if err := db.Get(&exists, `SELECT EXISTS (SELECT 1 FROM users WHERE email = $1)`, email); err != nil || exists {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	user := models.User{ID: uuid.New().String(), Email: email, PasswordHash: string(hashedPassword)}
+	if err := insertUser(db, &user, models.RoleAdmin); err != nil && !isUniqueViolation(err) {
+		return err
+	}
+	return nil
+}
+
+// insertUser creates the account with the given role, linked to the
+// unlinked employee record with the same email, if any. When several
+// employees share the email up to case, the exact match wins, then the
+// oldest record.
+func insertUser(db sqlx.Queryer, user *models.User, role string) error {
+	// $2 is cast everywhere so Postgres deduces a single type for it.
+	query := `INSERT INTO users (id, email, password_hash, role, employee_id)
+	          VALUES ($1, $2::varchar, $3, $4,
+	                  (SELECT e.id FROM employees e
+	                   WHERE lower(e.email) = lower($2::varchar)
+	                   AND NOT EXISTS (SELECT 1 FROM users u WHERE u.employee_id = e.id)
+	                   ORDER BY e.email = $2::varchar DESC, e.created_at, e.id
+	                   LIMIT 1))
+	          RETURNING role, employee_id, created_at`
+	return db.QueryRowx(query, user.ID, user.Email, user.PasswordHash, role).
+		Scan(&user.Role, &user.EmployeeID, &user.CreatedAt)
 }
 
 func (h *AuthHandler) Login(c *gin.Context) {
@@ -94,13 +148,13 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	updateQuery := `UPDATE users SET last_login = $1 WHERE id = $2`
 	h.db.Exec(updateQuery, now, user.ID)
 
-	token, err := generateToken(user.ID)
+	response, err := h.startSession(c, user)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
 		return
 	}
 
-	c.JSON(http.StatusOK, AuthResponse{Token: token, User: &user})
+	c.JSON(http.StatusOK, response)
 }
 
 func (h *AuthHandler) GetMe(c *gin.Context) {
@@ -120,16 +174,31 @@ func (h *AuthHandler) GetMe(c *gin.Context) {
 	c.JSON(http.StatusOK, user)
 }
 
-func generateToken(userID string) (string, error) {
+func generateToken(user models.User, sessionID string) (string, error) {
 	secret := os.Getenv("JWT_SECRET")
 	if secret == "" {
 		secret = "default-secret-key"
 	}
 
+	employeeID := ""
+	if user.EmployeeID != nil {
+		employeeID = *user.EmployeeID
+	}
+
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id": userID,
-		"exp":     time.Now().Add(24 * time.Hour).Unix(),
+		"user_id":     user.ID,
+		"role":        user.Role,
+		"employee_id": employeeID,
+		"session_id":  sessionID,
+		"exp":         time.Now().Add(accessTokenTTL).Unix(),
 	})
 
 	return token.SignedString([]byte(secret))
 }
+
+// isUniqueViolation reports whether err is Postgres rejecting a duplicate
+// value for a unique constraint.
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aalsa/management_dashboard/internal/models"
+	"github.com/lib/pq"
+)
+
+func TestIsUniqueViolation(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"unique violation", &pq.Error{Code: "23505"}, true},
+		{"wrapped unique violation", fmt.Errorf("linking: %w", &pq.Error{Code: "23505"}), true},
+		{"foreign key violation", &pq.Error{Code: "23503"}, false},
+		{"connection error", errors.New("connection refused"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isUniqueViolation(tt.err); got != tt.want {
+				t.Errorf("isUniqueViolation(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func insertUserStep(role string) fakeStep {
+	return fakeStep{match: "INSERT INTO users", columns: []string{"role", "employee_id", "created_at"},
+		rows: [][]driver.Value{{role, nil, time.Now()}}}
+}
+
+func TestRegisterStartsAsMember(t *testing.T) {
+	db, f := newFakeDB(t, insertUserStep(models.RoleMember), fakeStep{match: "INSERT INTO sessions"})
+
+	w := serve(NewAuthHandler(db).Register, `{"email": "first@example.com", "password": "secret1"}`, nil)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Register() = %d %s, want %d", w.Code, w.Body, http.StatusCreated)
+	}
+	if role := f.args[0][3]; role != models.RoleMember {
+		t.Errorf("registered with role %v, want %s", role, models.RoleMember)
+	}
+}
+
+func TestEnsureAdmin(t *testing.T) {
+	exists := func(found bool) fakeStep {
+		return fakeStep{match: "SELECT EXISTS", columns: []string{"exists"}, rows: [][]driver.Value{{found}}}
+	}
+
+	tests := []struct {
+		name     string
+		email    string
+		password string
+		steps    []fakeStep
+		wantErr  bool
+	}{
+		{"not configured", "", "", nil, false},
+		{"short password", "admin@example.com", "123", nil, true},
+		{"already exists", "admin@example.com", "secret1", []fakeStep{exists(true)}, false},
+		{"created", "admin@example.com", "secret1", []fakeStep{exists(false), insertUserStep(models.RoleAdmin)}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("ADMIN_EMAIL", tt.email)
+			t.Setenv("ADMIN_PASSWORD", tt.password)
+			db, f := newFakeDB(t, tt.steps...)
+
+			if err := EnsureAdmin(db); (err != nil) != tt.wantErr {
+				t.Fatalf("EnsureAdmin() error = %v, want error %v", err, tt.wantErr)
+			}
+			if len(f.args) == 2 && f.args[1][3] != models.RoleAdmin {
+				t.Errorf("created with role %v, want %s", f.args[1][3], models.RoleAdmin)
+			}
+		})
+	}
+}
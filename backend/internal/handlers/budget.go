@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BudgetResponse struct {
+	ProjectID       string   `json:"project_id"`
+	Budget          *float64 `json:"budget"`
+	Spent           float64  `json:"spent"`
+	Remaining       *float64 `json:"remaining"`
+	ConsumedPercent *float64 `json:"consumed_percent"`
+	Hours           float64  `json:"hours"`
+	UnratedHours    float64  `json:"unrated_hours"`
+	BurnRate        float64  `json:"burn_rate_per_week"`
+	BurnWeeks       int      `json:"burn_weeks"`
+	ExhaustionDate  *string  `json:"projected_exhaustion_date"`
+	AlertPercent    float64  `json:"alert_percent"`
+	OverBudget      bool     `json:"over_budget"`
+}
+
+type projectSpend struct {
+	Spent        float64 `db:"spent"`
+	Hours        float64 `db:"hours"`
+	UnratedHours float64 `db:"unrated_hours"`
+	RecentSpent  float64 `db:"recent_spent"`
+}
+
+// GetBudget prices the project's time logs at each employee's rate on the
+// day of the log. The burn rate is the weekly average over the last
+// ?burn_weeks (default 4) and projects when the remaining budget runs out.
+// over_budget is set once ?alert_percent (default 90) of the budget is spent.
+func (h *ProjectHandler) GetBudget(c *gin.Context) {
+	id := c.Param("id")
+
+	alertPercent := 90.0
+	if value := c.Query("alert_percent"); value != "" {
+		p, err := strconv.ParseFloat(value, 64)
+		if err != nil || p <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "alert_percent must be a positive percentage"})
+			return
+		}
+		alertPercent = p
+	}
+
+	burnWeeks := 4
+	if value := c.Query("burn_weeks"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > 52 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "burn_weeks must be between 1 and 52"})
+			return
+		}
+		burnWeeks = n
+	}
+
+	var budget *float64
+	if err := h.db.Get(&budget, `SELECT budget FROM projects WHERE id = $1`, id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		return
+	}
+
+	var spend projectSpend
+	query := `SELECT COALESCE(SUM(l.hours * r.hourly_rate), 0) AS spent,
+	                 COALESCE(SUM(l.hours), 0) AS hours,
+	                 COALESCE(SUM(l.hours) FILTER (WHERE r.hourly_rate IS NULL), 0) AS unrated_hours,
+	                 COALESCE(SUM(l.hours * r.hourly_rate) FILTER (WHERE l.log_date > CURRENT_DATE - $2 * 7), 0) AS recent_spent
+	          FROM time_logs l
+	          JOIN tasks t ON t.id = l.task_id` + rateAt + `
+	          WHERE t.project_id = $1`
+	if err := h.db.Get(&spend, query, id, burnWeeks); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := BudgetResponse{
+		ProjectID:    id,
+		Budget:       budget,
+		Spent:        spend.Spent,
+		Hours:        spend.Hours,
+		UnratedHours: spend.UnratedHours,
+		BurnRate:     math.Round(spend.RecentSpent/float64(burnWeeks)*100) / 100,
+		BurnWeeks:    burnWeeks,
+		AlertPercent: alertPercent,
+	}
+
+	if budget != nil {
+		remaining := *budget - spend.Spent
+		response.Remaining = &remaining
+
+		if *budget > 0 {
+			consumed := math.Round(spend.Spent / *budget * 1000) / 10
+			response.ConsumedPercent = &consumed
+			response.OverBudget = consumed >= alertPercent
+		} else {
+			response.OverBudget = spend.Spent > 0
+		}
+
+		if remaining > 0 && response.BurnRate > 0 {
+			days := math.Ceil(remaining / (response.BurnRate / 7))
+			date := time.Now().AddDate(0, 0, int(days)).Format("2006-01-02")
+			response.ExhaustionDate = &date
+		}
+	}
+
+	respondReport(c, response, csvSection{"budget", []BudgetResponse{response}})
+}
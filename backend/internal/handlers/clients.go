@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/aalsa/management_dashboard/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+type ClientHandler struct {
+	db *sqlx.DB
+}
+
+func NewClientHandler(db *sqlx.DB) *ClientHandler {
+	return &ClientHandler{db: db}
+}
+
+type ClientRequest struct {
+	Name       string  `json:"name" binding:"required,max=255"`
+	Email      *string `json:"email" binding:"omitempty,email"`
+	Address    *string `json:"address"`
+	HourlyRate float64 `json:"hourly_rate" binding:"gte=0"`
+}
+
+var clientSortFields = map[string]string{
+	"name":        "name",
+	"hourly_rate": "hourly_rate",
+	"created_at":  "created_at",
+}
+
+func (h *ClientHandler) GetAll(c *gin.Context) {
+	q := newListQuery(c, clientSortFields, "name")
+	q.text("name", "name ILIKE '%' || ? || '%'")
+
+	clients := []models.Client{}
+	listPage(c, h.db, &clients, "clients", q)
+}
+
+func (h *ClientHandler) GetByID(c *gin.Context) {
+	var client models.Client
+	if err := h.db.Get(&client, `SELECT * FROM clients WHERE id = $1`, c.Param("id")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Client not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, client)
+}
+
+func (h *ClientHandler) Create(c *gin.Context) {
+	var req ClientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var client models.Client
+	query := `INSERT INTO clients (name, email, address, hourly_rate)
+	          VALUES ($1, $2, $3, $4) RETURNING *`
+	if err := h.db.Get(&client, query, req.Name, req.Email, req.Address, req.HourlyRate); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, client)
+}
+
+func (h *ClientHandler) Update(c *gin.Context) {
+	var req ClientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	query := `UPDATE clients SET name = $1, email = $2, address = $3, hourly_rate = $4,
+	          updated_at = CURRENT_TIMESTAMP
+	          WHERE id = $5`
+	result, err := h.db.Exec(query, req.Name, req.Email, req.Address, req.HourlyRate, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Client not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Client updated"})
+}
+
+// Delete detaches the client's projects. Clients that have been invoiced
+// cannot be deleted.
+func (h *ClientHandler) Delete(c *gin.Context) {
+	id := c.Param("id")
+
+	var invoiced bool
+	if err := h.db.Get(&invoiced, `SELECT EXISTS (SELECT 1 FROM invoices WHERE client_id = $1)`, id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if invoiced {
+		c.JSON(http.StatusConflict, gin.H{"error": "Clients with invoices cannot be deleted"})
+		return
+	}
+
+	result, err := h.db.Exec(`DELETE FROM clients WHERE id = $1`, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Client not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Client deleted"})
+}
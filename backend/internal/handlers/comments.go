@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/aalsa/management_dashboard/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Mentions are written as @ followed by the employee's email, e.g. @jane@example.com.
+var mentionPattern = regexp.MustCompile(`@([A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,})`)
+
+type CommentHandler struct {
+	db *sqlx.DB
+}
+
+func NewCommentHandler(db *sqlx.DB) *CommentHandler {
+	return &CommentHandler{db: db}
+}
+
+type CommentRequest struct {
+	Body string `json:"body" binding:"required"`
+}
+
+func (h *CommentHandler) GetAll(c *gin.Context) {
+	taskID := c.Param("id")
+
+	var exists bool
+	if err := h.db.Get(&exists, `SELECT EXISTS (SELECT 1 FROM tasks WHERE id = $1)`, taskID); err != nil || !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+
+	comments := []models.TaskComment{}
+	query := `SELECT tc.*, u.email AS author_email
+	          FROM task_comments tc
+	          LEFT JOIN users u ON u.id = tc.author_id
+	          WHERE tc.task_id = $1
+	          ORDER BY tc.created_at`
+
+	if err := h.db.Select(&comments, query, taskID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, comments)
+}
+
+func (h *CommentHandler) Create(c *gin.Context) {
+	taskID := c.Param("id")
+	var req CommentRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var exists bool
+	if err := h.db.Get(&exists, `SELECT EXISTS (SELECT 1 FROM tasks WHERE id = $1)`, taskID); err != nil || !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+
+	userID := c.GetString("userID")
+	comment := models.TaskComment{
+		ID:       uuid.New().String(),
+		TaskID:   taskID,
+		AuthorID: &userID,
+		Body:     req.Body,
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO task_comments (id, task_id, author_id, body) VALUES ($1, $2, $3, $4)
+	          RETURNING created_at, updated_at`
+	if err := tx.QueryRow(query, comment.ID, comment.TaskID, userID, comment.Body).
+		Scan(&comment.CreatedAt, &comment.UpdatedAt); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.finish(tx, &comment); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, comment)
+}
+
+func (h *CommentHandler) Update(c *gin.Context) {
+	id := c.Param("id")
+	var req CommentRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer tx.Rollback()
+
+	var comment models.TaskComment
+	query := `UPDATE task_comments SET body = $1, updated_at = CURRENT_TIMESTAMP
+	          WHERE id = $2 AND author_id = $3 RETURNING *`
+	if err := tx.Get(&comment, query, req.Body, id, c.GetString("userID")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+		return
+	}
+
+	if err := h.finish(tx, &comment); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, comment)
+}
+
+func (h *CommentHandler) Delete(c *gin.Context) {
+	id := c.Param("id")
+
+	query := `DELETE FROM task_comments WHERE id = $1 AND author_id = $2`
+	result, err := h.db.Exec(query, id, c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Comment deleted"})
+}
+
+// finish syncs the comment's mentions with its body, notifies newly mentioned
+// people and commits.
+func (h *CommentHandler) finish(tx *sqlx.Tx, comment *models.TaskComment) error {
+	var author struct {
+		Email     string `db:"email"`
+		TaskTitle string `db:"title"`
+	}
+	query := `SELECT u.email, t.title FROM users u, tasks t WHERE u.id = $1 AND t.id = $2`
+	if err := tx.Get(&author, query, comment.AuthorID, comment.TaskID); err != nil {
+		return err
+	}
+	comment.AuthorEmail = &author.Email
+
+	emails := mentionedEmails(comment.Body)
+
+	staleQuery := `DELETE FROM comment_mentions WHERE comment_id = $1
+	               AND employee_id NOT IN (SELECT id FROM employees WHERE lower(email) = ANY($2))`
+	if _, err := tx.Exec(staleQuery, comment.ID, pq.Array(emails)); err != nil {
+		return err
+	}
+
+	var mentioned []string
+	mentionQuery := `INSERT INTO comment_mentions (comment_id, employee_id)
+	                 SELECT $1, id FROM employees WHERE lower(email) = ANY($2)
+	                 ON CONFLICT DO NOTHING RETURNING employee_id`
+	if err := tx.Select(&mentioned, mentionQuery, comment.ID, pq.Array(emails)); err != nil {
+		return err
+	}
+
+	if len(mentioned) > 0 {
+		message := fmt.Sprintf("%s mentioned you on %q", author.Email, author.TaskTitle)
+		notifyQuery := `INSERT INTO notifications (user_id, type, task_id, comment_id, message)
+		                SELECT id, 'mention', $1, $2, $3 FROM users
+		                WHERE employee_id = ANY($4) AND id <> $5`
+		if _, err := tx.Exec(notifyQuery, comment.TaskID, comment.ID, message,
+			pq.Array(mentioned), comment.AuthorID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func mentionedEmails(body string) []string {
+	seen := make(map[string]bool)
+	emails := []string{}
+	for _, match := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		email := strings.ToLower(match[1])
+		if !seen[email] {
+			seen[email] = true
+			emails = append(emails, email)
+		}
+	}
+	return emails
+}
@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMentionedEmails(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{"none", "Looks good to me", []string{}},
+		{"plain email is not a mention", "Mail ana@example.com for access", []string{}},
+		{"one mention", "@ana@example.com can you review?", []string{"ana@example.com"}},
+		{"trailing punctuation", "Thanks, @ana@example.com.", []string{"ana@example.com"}},
+		{"lowercased", "@Ana.Lopez@Example.COM", []string{"ana.lopez@example.com"}},
+		{"order kept and duplicates dropped", "@b@example.com @a@example.com @B@example.com",
+			[]string{"b@example.com", "a@example.com"}},
+		{"plus and dash", "(@dev+ops@my-team.example.org)", []string{"dev+ops@my-team.example.org"}},
+		{"missing top-level domain", "@ana@localhost", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mentionedEmails(tt.body); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mentionedEmails(%q) = %v, want %v", tt.body, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+// csvFlushRows is how many rows are written between flushes while streaming.
+const csvFlushRows = 100
+
+// csvSection is one table of a report that can be exported as CSV. rows must
+// be a slice of structs.
+type csvSection struct {
+	name string
+	rows interface{}
+}
+
+// wantsCSV reports whether the caller asked for CSV with ?format=csv or an
+// Accept: text/csv header.
+func wantsCSV(c *gin.Context) bool {
+	if format := c.Query("format"); format != "" {
+		return format == "csv"
+	}
+	return strings.Contains(c.GetHeader("Accept"), "text/csv")
+}
+
+// respondReport writes value as JSON, or one of its sections as CSV when
+// CSV was requested. The section is chosen with ?section= and defaults to
+// the first one.
+func respondReport(c *gin.Context, value interface{}, sections ...csvSection) {
+	if !wantsCSV(c) {
+		c.JSON(http.StatusOK, value)
+		return
+	}
+
+	section := sections[0]
+	if name := c.Query("section"); name != "" {
+		names := make([]string, len(sections))
+		found := false
+		for i, s := range sections {
+			names[i] = s.name
+			if s.name == name {
+				section, found = s, true
+			}
+		}
+		if !found {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "section must be one of " + strings.Join(names, ", ")})
+			return
+		}
+	}
+
+	rows := reflect.ValueOf(section.rows)
+	w := startCSV(c, rows.Type().Elem(), section.name)
+	for i := 0; i < rows.Len(); i++ {
+		w.Write(csvRecord(rows.Index(i)))
+	}
+	w.Flush()
+}
+
+// streamCSV writes every row of the query as CSV, scanning one row at a time
+// into a new value of elemType so large exports are never held in memory.
+func streamCSV(c *gin.Context, db *sqlx.DB, elemType reflect.Type, query string, args ...interface{}) {
+	rows, err := db.Queryx(query, args...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	w := startCSV(c, elemType, "")
+	count := 0
+	for rows.Next() {
+		row := reflect.New(elemType)
+		if err := rows.StructScan(row.Interface()); err != nil {
+			// The status line has already been sent, so the export can only
+			// stop short.
+			log.Println("CSV export failed:", err)
+			break
+		}
+		w.Write(csvRecord(row.Elem()))
+
+		count++
+		if count%csvFlushRows == 0 {
+			w.Flush()
+			c.Writer.Flush()
+		}
+	}
+	if err := rows.Err(); err != nil {
+		log.Println("CSV export failed:", err)
+	}
+	w.Flush()
+}
+
+// startCSV sends the CSV headers and the header row for elemType's columns.
+// The file is named after the route, e.g. /api/projects/:id/budget becomes
+// projects-budget.csv, plus the section when there is one.
+func startCSV(c *gin.Context, elemType reflect.Type, section string) *csv.Writer {
+	var parts []string
+	for _, part := range strings.Split(strings.TrimPrefix(c.FullPath(), "/api/"), "/") {
+		if part != "" && !strings.HasPrefix(part, ":") {
+			parts = append(parts, part)
+		}
+	}
+	if section != "" {
+		parts = append(parts, section)
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.csv"`, strings.Join(parts, "-")))
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	w.Write(csvHeader(elemType))
+	return w
+}
+
+// csvHeader lists the JSON names of the struct's fields in declaration
+// order, flattening embedded structs, so columns match the JSON output.
+func csvHeader(t reflect.Type) []string {
+	var header []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			header = append(header, csvHeader(field.Type)...)
+			continue
+		}
+		if name, ok := csvColumn(field); ok {
+			header = append(header, name)
+		}
+	}
+	return header
+}
+
+func csvRecord(v reflect.Value) []string {
+	var record []string
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			record = append(record, csvRecord(v.Field(i))...)
+			continue
+		}
+		if _, ok := csvColumn(field); ok {
+			record = append(record, csvValue(v.Field(i), csvDateColumns[field.Tag.Get("db")]))
+		}
+	}
+	return record
+}
+
+func csvColumn(field reflect.StructField) (string, bool) {
+	if !field.IsExported() {
+		return "", false
+	}
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if name == "-" {
+		return "", false
+	}
+	if name == "" {
+		name = field.Name
+	}
+	return name, true
+}
+
+// csvDateColumns are the columns, by db tag, that hold calendar dates rather
+// than timestamps.
+var csvDateColumns = map[string]bool{
+	"hire_date": true, "start_date": true, "end_date": true, "due_date": true, "log_date": true,
+	"week_start": true, "effective_from": true, "period_start": true, "period_end": true,
+	"date": true, "day": true, "week": true, "period": true,
+}
+
+// csvFormulaPrefixes start cells that spreadsheets would evaluate.
+const csvFormulaPrefixes = "=+-@\t\r"
+
+// csvValue formats one cell. Date columns are written as YYYY-MM-DD and
+// other timestamps as YYYY-MM-DD HH:MM:SS in UTC; nil values are left
+// empty. Text that a spreadsheet would run as a formula is prefixed with '.
+func csvValue(v reflect.Value, date bool) string {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+
+	if t, ok := v.Interface().(time.Time); ok {
+		return csvTime(t, date)
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		s := v.String()
+		if date {
+			// DATE columns are scanned as RFC 3339 strings at midnight UTC.
+			if t, err := time.Parse(time.RFC3339, s); err == nil {
+				return csvTime(t, true)
+			}
+			return s
+		}
+		if s != "" && strings.ContainsRune(csvFormulaPrefixes, rune(s[0])) {
+			return "'" + s
+		}
+		return s
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.String {
+			items := make([]string, v.Len())
+			for i := range items {
+				items[i] = v.Index(i).String()
+			}
+			return strings.Join(items, ";")
+		}
+	}
+
+	b, _ := json.Marshal(v.Interface())
+	return string(b)
+}
+
+func csvTime(t time.Time, date bool) string {
+	if date {
+		return t.UTC().Format("2006-01-02")
+	}
+	return t.UTC().Format("2006-01-02 15:04:05")
+}
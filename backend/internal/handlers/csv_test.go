@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCSVValue(t *testing.T) {
+	text := "notes"
+	var missing *string
+	stamp := time.Date(2024, 3, 5, 14, 30, 0, 0, time.FixedZone("CET", 3600))
+
+	tests := []struct {
+		name  string
+		value interface{}
+		date  bool
+		want  string
+	}{
+		{"text", "plain", false, "plain"},
+		{"formula", "=SUM(A1:A2)", false, "'=SUM(A1:A2)"},
+		{"plus", "+1", false, "'+1"},
+		{"minus", "-1", false, "'-1"},
+		{"at sign", "@cmd", false, "'@cmd"},
+		{"empty", "", false, ""},
+		{"pointer", &text, false, "notes"},
+		{"nil pointer", missing, false, ""},
+		{"bool", true, false, "true"},
+		{"int", 42, false, "42"},
+		{"float", 7.25, false, "7.25"},
+		{"whole float", 8.0, false, "8"},
+		{"timestamp in UTC", stamp, false, "2024-03-05 13:30:00"},
+		{"date column timestamp", stamp, true, "2024-03-05"},
+		{"date column string", "2024-03-05T00:00:00Z", true, "2024-03-05"},
+		{"date column other string", "soon", true, "soon"},
+		{"string list", []string{"admin", "manager"}, false, "admin;manager"},
+		{"map", map[string]int{"a": 1}, false, `{"a":1}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := csvValue(reflect.ValueOf(tt.value), tt.date); got != tt.want {
+				t.Errorf("csvValue(%v) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCSVHeader(t *testing.T) {
+	type inner struct {
+		ID   string `json:"id"`
+		Name string `json:"name,omitempty"`
+	}
+	type row struct {
+		inner
+		Hours    float64 `json:"hours"`
+		Secret   string  `json:"-"`
+		Untagged bool
+		private  int
+	}
+
+	tests := []struct {
+		name string
+		typ  reflect.Type
+		want []string
+	}{
+		{"flat", reflect.TypeOf(inner{}), []string{"id", "name"}},
+		{"embedded and skipped fields", reflect.TypeOf(row{}), []string{"id", "name", "hours", "Untagged"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := csvHeader(tt.typ); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("csvHeader() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
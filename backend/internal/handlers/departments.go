@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/aalsa/management_dashboard/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+type DepartmentHandler struct {
+	db *sqlx.DB
+}
+
+func NewDepartmentHandler(db *sqlx.DB) *DepartmentHandler {
+	return &DepartmentHandler{db: db}
+}
+
+type DepartmentRequest struct {
+	Name              string  `json:"name" binding:"required,max=100"`
+	HeadID            *string `json:"head_id"`
+	HolidayCalendarID *string `json:"holiday_calendar_id"`
+}
+
+// departmentsTable adds the headcount to each department.
+const departmentsTable = `(SELECT d.*,
+	(SELECT COUNT(*) FROM employees e WHERE e.department_id = d.id) AS employees
+	FROM departments d) AS departments`
+
+var departmentSortFields = map[string]string{
+	"name":       "name",
+	"employees":  "employees",
+	"created_at": "created_at",
+}
+
+func (h *DepartmentHandler) GetAll(c *gin.Context) {
+	q := newListQuery(c, departmentSortFields, "name")
+	q.text("name", "name ILIKE '%' || ? || '%'")
+
+	departments := []models.Department{}
+	listPage(c, h.db, &departments, departmentsTable, q)
+}
+
+func (h *DepartmentHandler) GetByID(c *gin.Context) {
+	var department models.Department
+	query := `SELECT * FROM ` + departmentsTable + ` WHERE id = $1`
+	if err := h.db.Get(&department, query, c.Param("id")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Department not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, department)
+}
+
+func (h *DepartmentHandler) Create(c *gin.Context) {
+	var req DepartmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if status, problem, err := h.check("", req); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	} else if problem != "" {
+		c.JSON(status, gin.H{"error": problem})
+		return
+	}
+
+	var department models.Department
+	query := `INSERT INTO departments (name, head_id, holiday_calendar_id) VALUES ($1, $2, $3)
+	          RETURNING *, 0 AS employees`
+	if err := h.db.Get(&department, query, strings.TrimSpace(req.Name), req.HeadID, req.HolidayCalendarID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, department)
+}
+
+func (h *DepartmentHandler) Update(c *gin.Context) {
+	id := c.Param("id")
+	var req DepartmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if status, problem, err := h.check(id, req); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	} else if problem != "" {
+		c.JSON(status, gin.H{"error": problem})
+		return
+	}
+
+	query := `UPDATE departments SET name = $1, head_id = $2, holiday_calendar_id = $3,
+	          updated_at = CURRENT_TIMESTAMP
+	          WHERE id = $4`
+	result, err := h.db.Exec(query, strings.TrimSpace(req.Name), req.HeadID, req.HolidayCalendarID, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Department not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Department updated"})
+}
+
+// Delete removes an empty department. Its teams are kept without a
+// department.
+func (h *DepartmentHandler) Delete(c *gin.Context) {
+	id := c.Param("id")
+
+	var staffed bool
+	if err := h.db.Get(&staffed, `SELECT EXISTS (SELECT 1 FROM employees WHERE department_id::text = $1)`, id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if staffed {
+		c.JSON(http.StatusConflict, gin.H{"error": "Departments with employees cannot be deleted"})
+		return
+	}
+
+	result, err := h.db.Exec(`DELETE FROM departments WHERE id = $1`, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Department not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Department deleted"})
+}
+
+// check rejects blank or duplicate names (ignoring case), heads that are not
+// employees and missing holiday calendars. id is empty for new departments.
+func (h *DepartmentHandler) check(id string, req DepartmentRequest) (int, string, error) {
+	if strings.TrimSpace(req.Name) == "" {
+		return http.StatusBadRequest, "name is required", nil
+	}
+
+	var taken bool
+	query := `SELECT EXISTS (SELECT 1 FROM departments WHERE lower(name) = lower($1) AND id::text <> $2)`
+	if err := h.db.Get(&taken, query, strings.TrimSpace(req.Name), id); err != nil {
+		return 0, "", err
+	}
+	if taken {
+		return http.StatusConflict, "A department with this name already exists", nil
+	}
+
+	if problem, err := checkEmployeeRef(h.db, req.HeadID, "Head"); err != nil || problem != "" {
+		return http.StatusBadRequest, problem, err
+	}
+
+	if req.HolidayCalendarID != nil {
+		var exists bool
+		query := `SELECT EXISTS (SELECT 1 FROM holiday_calendars WHERE id::text = $1)`
+		if err := h.db.Get(&exists, query, *req.HolidayCalendarID); err != nil {
+			return 0, "", err
+		}
+		if !exists {
+			return http.StatusBadRequest, "Holiday calendar not found", nil
+		}
+	}
+	return 0, "", nil
+}
+
+// checkEmployeeRef reports a problem when an optional employee reference,
+// described by label, does not exist.
+func checkEmployeeRef(db sqlx.Queryer, employeeID *string, label string) (string, error) {
+	if employeeID == nil {
+		return "", nil
+	}
+	var exists bool
+	if err := sqlx.Get(db, &exists, `SELECT EXISTS (SELECT 1 FROM employees WHERE id::text = $1)`, *employeeID); err != nil {
+		return "", err
+	}
+	if !exists {
+		return label + " not found", nil
+	}
+	return "", nil
+}
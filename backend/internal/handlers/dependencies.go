@@ -0,0 +1,284 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/aalsa/management_dashboard/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+type AddDependencyRequest struct {
+	BlockedByID string `json:"blocked_by_id" binding:"required"`
+}
+
+type Blocker struct {
+	models.Task
+	LinkedAt time.Time `db:"linked_at" json:"linked_at"`
+}
+
+type CriticalPathResponse struct {
+	ProjectID      string        `json:"project_id"`
+	Length         int           `json:"length"`
+	EstimatedHours float64       `json:"estimated_hours"`
+	FinishDate     *string       `json:"finish_date"`
+	Tasks          []models.Task `json:"tasks"`
+}
+
+// chain is the best path found so far ending at a task.
+type chain struct {
+	hours  float64
+	length int
+}
+
+func (a chain) longerThan(b chain) bool {
+	if a.hours != b.hours {
+		return a.hours > b.hours
+	}
+	return a.length > b.length
+}
+
+func (h *TaskHandler) GetDependencies(c *gin.Context) {
+	id := c.Param("id")
+
+	var exists bool
+	if err := h.db.Get(&exists, `SELECT EXISTS (SELECT 1 FROM tasks WHERE id = $1)`, id); err != nil || !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+
+	blockers := []Blocker{}
+	query := `SELECT t.*, d.created_at AS linked_at
+	          FROM task_dependencies d
+	          JOIN tasks t ON t.id = d.blocked_by_id
+	          WHERE d.task_id = $1
+	          ORDER BY t.due_date, t.title`
+
+	if err := h.db.Select(&blockers, query, id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, blockers)
+}
+
+func (h *TaskHandler) AddDependency(c *gin.Context) {
+	id := c.Param("id")
+	var req AddDependencyRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var task models.Task
+	if err := h.db.Get(&task, `SELECT * FROM tasks WHERE id = $1`, id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+
+	var exists bool
+	if err := h.db.Get(&exists, `SELECT EXISTS (SELECT 1 FROM tasks WHERE id = $1)`, req.BlockedByID); err != nil || !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Blocking task not found"})
+		return
+	}
+
+	if !canAccessTask(c, h.db, task.AssignedTo) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You cannot manage tasks for this assignee"})
+		return
+	}
+
+	if req.BlockedByID == id {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "A task cannot block itself"})
+		return
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer tx.Rollback()
+
+	// Serialize dependency changes so two concurrent links cannot form a cycle.
+	if _, err := tx.Exec(`LOCK TABLE task_dependencies IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// The link closes a cycle if the new blocker already (transitively) waits on this task.
+	var cycle bool
+	cycleQuery := `WITH RECURSIVE chain AS (
+	                   SELECT blocked_by_id FROM task_dependencies WHERE task_id = $1
+	                   UNION
+	                   SELECT d.blocked_by_id FROM task_dependencies d JOIN chain ON d.task_id = chain.blocked_by_id
+	               )
+	               SELECT EXISTS (SELECT 1 FROM chain WHERE blocked_by_id = $2)`
+	if err := tx.Get(&cycle, cycleQuery, req.BlockedByID, id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if cycle {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "This dependency would create a cycle"})
+		return
+	}
+
+	dependency := models.TaskDependency{TaskID: id, BlockedByID: req.BlockedByID}
+	query := `INSERT INTO task_dependencies (task_id, blocked_by_id) VALUES ($1, $2)
+	          ON CONFLICT (task_id, blocked_by_id) DO UPDATE SET created_at = task_dependencies.created_at
+	          RETURNING created_at`
+	if err := tx.QueryRow(query, dependency.TaskID, dependency.BlockedByID).Scan(&dependency.CreatedAt); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, dependency)
+}
+
+func (h *TaskHandler) RemoveDependency(c *gin.Context) {
+	id := c.Param("id")
+	blockerID := c.Param("blockerId")
+
+	var task models.Task
+	if err := h.db.Get(&task, `SELECT * FROM tasks WHERE id = $1`, id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+
+	if !canAccessTask(c, h.db, task.AssignedTo) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You cannot manage tasks for this assignee"})
+		return
+	}
+
+	query := `DELETE FROM task_dependencies WHERE task_id = $1 AND blocked_by_id = $2`
+	result, err := h.db.Exec(query, id, blockerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Dependency not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Dependency removed"})
+}
+
+// GetCriticalPath returns the chain of open, dependent tasks in the project with
+// the most estimated hours. Unestimated tasks count as zero hours, so chain
+// length and then the later due date at the end of the chain break ties.
+func (h *TaskHandler) GetCriticalPath(c *gin.Context) {
+	projectID := c.Param("id")
+
+	var exists bool
+	if err := h.db.Get(&exists, `SELECT EXISTS (SELECT 1 FROM projects WHERE id = $1)`, projectID); err != nil || !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		return
+	}
+
+	var tasks []models.Task
+	query := `SELECT * FROM tasks WHERE project_id = $1 AND status <> 'completed'`
+	if err := h.db.Select(&tasks, query, projectID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var dependencies []models.TaskDependency
+	depQuery := `SELECT d.* FROM task_dependencies d
+	             JOIN tasks t ON t.id = d.task_id
+	             JOIN tasks b ON b.id = d.blocked_by_id
+	             WHERE t.project_id = $1 AND b.project_id = $1
+	             AND t.status <> 'completed' AND b.status <> 'completed'`
+	if err := h.db.Select(&dependencies, depQuery, projectID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	path := longestChain(tasks, dependencies)
+
+	response := CriticalPathResponse{ProjectID: projectID, Length: len(path), Tasks: path}
+	for _, task := range path {
+		response.EstimatedHours += estimate(task)
+		if task.DueDate != nil && (response.FinishDate == nil || *task.DueDate > *response.FinishDate) {
+			response.FinishDate = task.DueDate
+		}
+	}
+
+	respondReport(c, response, csvSection{"tasks", response.Tasks})
+}
+
+// longestChain walks the dependency graph in topological order, tracking the
+// heaviest chain of blockers ending at each task.
+func longestChain(tasks []models.Task, dependencies []models.TaskDependency) []models.Task {
+	byID := make(map[string]models.Task, len(tasks))
+	for _, task := range tasks {
+		byID[task.ID] = task
+	}
+
+	blocks := make(map[string][]string)
+	waitingOn := make(map[string]int)
+	for _, d := range dependencies {
+		blocks[d.BlockedByID] = append(blocks[d.BlockedByID], d.TaskID)
+		waitingOn[d.TaskID]++
+	}
+
+	best := make(map[string]chain, len(tasks))
+	prev := make(map[string]string)
+	var ready []string
+	for _, task := range tasks {
+		best[task.ID] = chain{hours: estimate(task), length: 1}
+		if waitingOn[task.ID] == 0 {
+			ready = append(ready, task.ID)
+		}
+	}
+
+	end := ""
+	for len(ready) > 0 {
+		id := ready[0]
+		ready = ready[1:]
+
+		if end == "" || best[id].longerThan(best[end]) ||
+			(!best[end].longerThan(best[id]) && laterDue(byID[id].DueDate, byID[end].DueDate)) {
+			end = id
+		}
+
+		for _, next := range blocks[id] {
+			candidate := chain{hours: best[id].hours + estimate(byID[next]), length: best[id].length + 1}
+			if candidate.longerThan(best[next]) {
+				best[next] = candidate
+				prev[next] = id
+			}
+			waitingOn[next]--
+			if waitingOn[next] == 0 {
+				ready = append(ready, next)
+			}
+		}
+	}
+
+	path := []models.Task{}
+	for id := end; id != ""; id = prev[id] {
+		path = append([]models.Task{byID[id]}, path...)
+	}
+	return path
+}
+
+func estimate(task models.Task) float64 {
+	if task.EstimatedHours == nil {
+		return 0
+	}
+	return *task.EstimatedHours
+}
+
+func laterDue(a, b *string) bool {
+	if a == nil {
+		return false
+	}
+	return b == nil || *a > *b
+}
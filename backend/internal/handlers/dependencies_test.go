@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aalsa/management_dashboard/internal/models"
+)
+
+func TestLongestChain(t *testing.T) {
+	task := func(id string, hours float64, due string) models.Task {
+		task := models.Task{ID: id}
+		if hours > 0 {
+			task.EstimatedHours = &hours
+		}
+		if due != "" {
+			task.DueDate = &due
+		}
+		return task
+	}
+	blocks := func(blocker, blocked string) models.TaskDependency {
+		return models.TaskDependency{TaskID: blocked, BlockedByID: blocker}
+	}
+
+	tests := []struct {
+		name         string
+		tasks        []models.Task
+		dependencies []models.TaskDependency
+		want         []string
+	}{
+		{"no tasks", nil, nil, []string{}},
+		{"single task", []models.Task{task("a", 3, "")}, nil, []string{"a"}},
+		{
+			"chain",
+			[]models.Task{task("c", 1, ""), task("b", 1, ""), task("a", 1, "")},
+			[]models.TaskDependency{blocks("a", "b"), blocks("b", "c")},
+			[]string{"a", "b", "c"},
+		},
+		{
+			"heavier task beats longer chain",
+			[]models.Task{task("a", 1, ""), task("b", 1, ""), task("c", 5, "")},
+			[]models.TaskDependency{blocks("a", "b")},
+			[]string{"c"},
+		},
+		{
+			"longer chain breaks an hours tie",
+			[]models.Task{task("a", 0, ""), task("b", 0, ""), task("c", 0, "")},
+			[]models.TaskDependency{blocks("a", "b")},
+			[]string{"a", "b"},
+		},
+		{
+			"later due date breaks a full tie",
+			[]models.Task{task("a", 2, "2024-03-01"), task("b", 2, "2024-04-01"), task("c", 2, "")},
+			nil,
+			[]string{"b"},
+		},
+		{
+			"joins keep the heavier blocker",
+			[]models.Task{task("a", 4, ""), task("b", 1, ""), task("c", 1, "")},
+			[]models.TaskDependency{blocks("a", "c"), blocks("b", "c")},
+			[]string{"a", "c"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []string{}
+			for _, task := range longestChain(tt.tasks, tt.dependencies) {
+				got = append(got, task.ID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("longestChain() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
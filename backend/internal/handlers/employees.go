@@ -1,10 +1,17 @@
 package handlers
 
 import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"net/mail"
+	"strings"
 
 	"github.com/aalsa/management_dashboard/internal/models"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 )
@@ -17,23 +24,40 @@ func NewEmployeeHandler(db *sqlx.DB) *EmployeeHandler {
 	return &EmployeeHandler{db: db}
 }
 
-func (h *EmployeeHandler) GetAll(c *gin.Context) {
-	var employees []models.Employee
-	query := `SELECT * FROM employees ORDER BY full_name`
+const (
+	defaultWeeklyCapacity = 40
+	maxWeeklyCapacity     = 168
+)
 
-	if err := h.db.Select(&employees, query); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
+// employeesTable adds the department name to each employee.
+const employeesTable = `(SELECT e.*, d.name AS department
+	FROM employees e JOIN departments d ON d.id = e.department_id) AS employees`
 
-	c.JSON(http.StatusOK, employees)
+var employeeSortFields = map[string]string{
+	"full_name":             "full_name",
+	"email":                 "email",
+	"department":            "department",
+	"hire_date":             "hire_date",
+	"weekly_capacity_hours": "weekly_capacity_hours",
+	"created_at":            "created_at",
+}
+
+func (h *EmployeeHandler) GetAll(c *gin.Context) {
+	q := newListQuery(c, employeeSortFields, "full_name")
+	q.id("department_id", "department_id = ?")
+	q.text("department", "lower(department) = lower(?)")
+	q.id("team_id", "id IN (SELECT employee_id FROM team_members WHERE team_id = ?)")
+	q.oneOf("status", "status")
+
+	employees := []models.Employee{}
+	listPage(c, h.db, &employees, employeesTable, q)
 }
 
 func (h *EmployeeHandler) GetByID(c *gin.Context) {
 	id := c.Param("id")
 	var employee models.Employee
 
-	query := `SELECT * FROM employees WHERE id = $1`
+	query := `SELECT * FROM ` + employeesTable + ` WHERE id = $1`
 	if err := h.db.Get(&employee, query, id); err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Employee not found"})
 		return
@@ -49,16 +73,33 @@ func (h *EmployeeHandler) Create(c *gin.Context) {
 		return
 	}
 
-	employee.ID = uuid.New().String()
+	if problem := validateEmployee(employee); problem != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": problem})
+		return
+	}
 
-	query := `INSERT INTO employees (id, email, full_name, role, department, hire_date, status)
-	          VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING created_at, updated_at`
+	if problem, err := resolveDepartment(h.db, &employee); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	} else if problem != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": problem})
+		return
+	}
 
-	err := h.db.QueryRow(query, employee.ID, employee.Email, employee.FullName,
-		employee.Role, employee.Department, employee.HireDate, employee.Status).
-		Scan(&employee.CreatedAt, &employee.UpdatedAt)
+	if !canAccessDepartment(c, h.db, employee.DepartmentID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only manage employees in your department"})
+		return
+	}
 
-	if err != nil {
+	if problem, err := checkManager(h.db, "", employee.ManagerID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	} else if problem != "" {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": problem})
+		return
+	}
+
+	if err := insertEmployee(h.db, &employee); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -70,35 +111,95 @@ func (h *EmployeeHandler) Update(c *gin.Context) {
 	id := c.Param("id")
 	var employee models.Employee
 
-	if err := c.ShouldBindJSON(&employee); err != nil {
+	managerGiven, err := bindEmployeeUpdate(c, &employee)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	query := `UPDATE employees SET email = $1, full_name = $2, role = $3,
-	          department = $4, hire_date = $5, status = $6, updated_at = CURRENT_TIMESTAMP
-	          WHERE id = $7`
+	if problem := validateEmployee(employee); problem != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": problem})
+		return
+	}
+
+	if problem, err := resolveDepartment(h.db, &employee); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	} else if problem != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": problem})
+		return
+	}
+
+	var currentDepartment string
+	if err := h.db.Get(&currentDepartment, `SELECT department_id FROM employees WHERE id = $1`, id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Employee not found"})
+		return
+	}
 
-	result, err := h.db.Exec(query, employee.Email, employee.FullName, employee.Role,
-		employee.Department, employee.HireDate, employee.Status, id)
+	// Managers reach their reports in any department, so the department is
+	// only checked when the update moves the employee.
+	if !canAccessEmployee(c, h.db, id) ||
+		(employee.DepartmentID != currentDepartment && !canAccessDepartment(c, h.db, employee.DepartmentID)) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only manage employees in your department"})
+		return
+	}
 
+	tx, err := h.db.Beginx()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer tx.Rollback()
+
+	// Clients that do not know about the org chart leave manager_id out; only
+	// an explicit null removes the manager.
+	if !managerGiven {
+		err := tx.Get(&employee.ManagerID, `SELECT manager_id FROM employees WHERE id = $1 FOR UPDATE`, id)
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Employee not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	if problem, err := lockAndCheckManager(tx, id, employee.ManagerID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	} else if problem != "" {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": problem})
+		return
+	}
+
+	rows, err := updateEmployee(tx, id, employee)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	rows, _ := result.RowsAffected()
 	if rows == 0 {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Employee not found"})
 		return
 	}
 
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{"message": "Employee updated"})
 }
 
 func (h *EmployeeHandler) Delete(c *gin.Context) {
 	id := c.Param("id")
 
+	if !canAccessEmployee(c, h.db, id) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only manage employees in your department"})
+		return
+	}
+
 	query := `DELETE FROM employees WHERE id = $1`
 	result, err := h.db.Exec(query, id)
 	if err != nil {
@@ -114,3 +215,116 @@ func (h *EmployeeHandler) Delete(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "Employee deleted"})
 }
+
+// bindEmployeeUpdate binds the body of an Update into employee and reports
+// whether it sets manager_id.
+func bindEmployeeUpdate(c *gin.Context, employee *models.Employee) (bool, error) {
+	if err := c.ShouldBindBodyWith(employee, binding.JSON); err != nil {
+		return false, err
+	}
+	var fields map[string]json.RawMessage
+	if err := c.ShouldBindBodyWith(&fields, binding.JSON); err != nil {
+		return false, err
+	}
+	_, given := fields["manager_id"]
+	return given, nil
+}
+
+// validateEmployee checks the fields shared by Create, Update and the CSV
+// import.
+func validateEmployee(employee models.Employee) string {
+	if _, err := mail.ParseAddress(employee.Email); err != nil {
+		return "email must be a valid email address"
+	}
+	if strings.TrimSpace(employee.FullName) == "" {
+		return "full_name is required"
+	}
+	if strings.TrimSpace(employee.Role) == "" {
+		return "role is required"
+	}
+	if employee.DepartmentID == "" && strings.TrimSpace(employee.Department) == "" {
+		return "department_id is required"
+	}
+	if _, err := parseDate(employee.HireDate); err != nil {
+		return "hire_date must be a date in YYYY-MM-DD format"
+	}
+	if employee.Status != "active" && employee.Status != "inactive" {
+		return "status must be active or inactive"
+	}
+	if capacity := employee.WeeklyCapacityHours; capacity != nil && (*capacity < 0 || *capacity > maxWeeklyCapacity) {
+		return fmt.Sprintf("weekly_capacity_hours must be between 0 and %d", maxWeeklyCapacity)
+	}
+	return ""
+}
+
+// resolveDepartment checks that the employee's department_id exists or, when
+// only a department name is given, looks it up regardless of case. Either way
+// both fields are filled in.
+func resolveDepartment(db sqlx.Queryer, employee *models.Employee) (string, error) {
+	var departments []models.Department
+	var err error
+	if employee.DepartmentID != "" {
+		err = sqlx.Select(db, &departments, `SELECT id, name FROM departments WHERE id::text = $1`, employee.DepartmentID)
+	} else {
+		err = sqlx.Select(db, &departments, `SELECT id, name FROM departments WHERE lower(name) = lower($1)`,
+			strings.TrimSpace(employee.Department))
+	}
+	if err != nil {
+		return "", err
+	}
+	if len(departments) == 0 {
+		return "Department not found", nil
+	}
+
+	employee.DepartmentID = departments[0].ID
+	employee.Department = departments[0].Name
+	return "", nil
+}
+
+// insertEmployee creates the employee, defaulting its capacity, and links an
+// unlinked account with the same email.
+func insertEmployee(db sqlx.Ext, employee *models.Employee) error {
+	employee.ID = uuid.New().String()
+	if employee.WeeklyCapacityHours == nil {
+		capacity := float64(defaultWeeklyCapacity)
+		employee.WeeklyCapacityHours = &capacity
+	}
+
+	query := `INSERT INTO employees (id, email, full_name, role, department_id, hire_date, status,
+	          weekly_capacity_hours, manager_id)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING created_at, updated_at`
+
+	err := db.QueryRowx(query, employee.ID, employee.Email, employee.FullName, employee.Role,
+		employee.DepartmentID, employee.HireDate, employee.Status, employee.WeeklyCapacityHours, employee.ManagerID).
+		Scan(&employee.CreatedAt, &employee.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	// Several accounts can share the email up to case; the exact match wins,
+	// then the oldest account.
+	linkQuery := `UPDATE users SET employee_id = $1
+	              WHERE id = (SELECT id FROM users
+	                          WHERE lower(email) = lower($2::varchar) AND employee_id IS NULL
+	                          ORDER BY email = $2::varchar DESC, created_at, id
+	                          LIMIT 1)`
+	_, err = db.Exec(linkQuery, employee.ID, employee.Email)
+	return err
+}
+
+// updateEmployee overwrites the employee's fields. An omitted capacity keeps
+// the current value.
+func updateEmployee(db sqlx.Execer, id string, employee models.Employee) (int64, error) {
+	query := `UPDATE employees SET email = $1, full_name = $2, role = $3,
+	          department_id = $4, hire_date = $5, status = $6,
+	          weekly_capacity_hours = COALESCE($7::numeric, weekly_capacity_hours),
+	          manager_id = $8, updated_at = CURRENT_TIMESTAMP
+	          WHERE id = $9`
+
+	result, err := db.Exec(query, employee.Email, employee.FullName, employee.Role, employee.DepartmentID,
+		employee.HireDate, employee.Status, employee.WeeklyCapacityHours, employee.ManagerID, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
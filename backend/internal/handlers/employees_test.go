@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aalsa/management_dashboard/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+func TestBindEmployeeUpdate(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		wantGiven   bool
+		wantManager string
+	}{
+		{"omitted", `{"full_name": "Ada"}`, false, ""},
+		{"null", `{"full_name": "Ada", "manager_id": null}`, true, ""},
+		{"set", `{"full_name": "Ada", "manager_id": "m1"}`, true, "m1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest("PUT", "/", strings.NewReader(tt.body))
+
+			var employee models.Employee
+			given, err := bindEmployeeUpdate(c, &employee)
+			if err != nil {
+				t.Fatalf("bindEmployeeUpdate(%s) error = %v", tt.body, err)
+			}
+			if given != tt.wantGiven {
+				t.Errorf("bindEmployeeUpdate(%s) = %v, want %v", tt.body, given, tt.wantGiven)
+			}
+			manager := ""
+			if employee.ManagerID != nil {
+				manager = *employee.ManagerID
+			}
+			if manager != tt.wantManager {
+				t.Errorf("manager_id = %q, want %q", manager, tt.wantManager)
+			}
+			if employee.FullName != "Ada" {
+				t.Errorf("full_name = %q, want Ada", employee.FullName)
+			}
+		})
+	}
+}
+
+func TestUpdateEmployeeDepartmentAccess(t *testing.T) {
+	tests := []struct {
+		name       string
+		department string
+		steps      []fakeStep
+		wantStatus int
+	}{
+		{"report in another department", "d2", []fakeStep{
+			{match: "UPDATE employees", affected: 1},
+		}, http.StatusOK},
+		{"moved into a department the manager cannot reach", "d3", []fakeStep{
+			{match: "FROM departments WHERE head_id", columns: []string{"allowed"}, rows: [][]driver.Value{{false}}},
+		}, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			steps := []fakeStep{
+				{match: "FROM departments WHERE id::text", columns: []string{"id", "name"},
+					rows: [][]driver.Value{{tt.department, "Sales"}}},
+				{match: "SELECT department_id FROM employees", columns: []string{"department_id"},
+					rows: [][]driver.Value{{"d2"}}},
+				{match: "WITH RECURSIVE chain", columns: []string{"exists"}, rows: [][]driver.Value{{true}}},
+			}
+			db, _ := newFakeDB(t, append(steps, tt.steps...)...)
+
+			body := `{"email": "ada@example.com", "full_name": "Ada", "role": "Engineer", "department_id": "` +
+				tt.department + `", "hire_date": "2024-01-15", "status": "active", "manager_id": null}`
+			w := serve(NewEmployeeHandler(db).Update, body, gin.Params{{Key: "id", Value: "e2"}},
+				"role", models.RoleManager, "employeeID", "e1")
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Update() = %d %s, want %d", w.Code, w.Body, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+// fakeStep answers one statement: the next statement run must contain match,
+// and gets back err or the columns and rows. Statements run with Exec report
+// affected rows.
+type fakeStep struct {
+	match    string
+	columns  []string
+	rows     [][]driver.Value
+	affected int64
+	err      error
+}
+
+// fakeDB is a database/sql driver that plays back a script of steps in
+// order, so handlers can run without Postgres. Arguments go through
+// database/sql's default conversion, as they do with lib/pq.
+type fakeDB struct {
+	t     *testing.T
+	mu    sync.Mutex
+	steps []fakeStep
+	next  int
+	// args holds the arguments of each statement run, by step.
+	args [][]driver.Value
+}
+
+// newFakeDB returns a database that expects exactly the given steps.
+func newFakeDB(t *testing.T, steps ...fakeStep) (*sqlx.DB, *fakeDB) {
+	t.Helper()
+	f := &fakeDB{t: t, steps: steps}
+	db := sqlx.NewDb(sql.OpenDB(f), "postgres")
+	t.Cleanup(func() {
+		db.Close()
+		if f.next < len(f.steps) {
+			t.Errorf("statement %q was never run", f.steps[f.next].match)
+		}
+	})
+	return db, f
+}
+
+func (f *fakeDB) Connect(context.Context) (driver.Conn, error) { return &fakeConn{f}, nil }
+func (f *fakeDB) Driver() driver.Driver                        { return fakeDriver{} }
+
+func (f *fakeDB) run(query string, args []driver.NamedValue) (fakeStep, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.next == len(f.steps) {
+		f.t.Errorf("unexpected statement %q", query)
+		return fakeStep{}, fmt.Errorf("unexpected statement")
+	}
+	step := f.steps[f.next]
+	if !strings.Contains(query, step.match) {
+		f.t.Errorf("statement %d is %q, want one containing %q", f.next, query, step.match)
+		return fakeStep{}, fmt.Errorf("unexpected statement")
+	}
+	f.next++
+
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	f.args = append(f.args, values)
+	return step, step.err
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) { return nil, fmt.Errorf("use sql.OpenDB") }
+
+type fakeConn struct{ db *fakeDB }
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) { return &fakeStmt{c, query}, nil }
+func (c *fakeConn) Close() error                              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)                 { return fakeTx{}, nil }
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	step, err := c.db.run(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return &fakeRows{columns: step.columns, rows: step.rows}, nil
+}
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	step, err := c.db.run(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(step.affected), nil
+}
+
+type fakeStmt struct {
+	conn  *fakeConn
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.conn.ExecContext(context.Background(), s.query, named(args))
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.conn.QueryContext(context.Background(), s.query, named(args))
+}
+
+func named(args []driver.Value) []driver.NamedValue {
+	values := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		values[i] = driver.NamedValue{Ordinal: i + 1, Value: arg}
+	}
+	return values
+}
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+	next    int
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.next == len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.next])
+	r.next++
+	return nil
+}
+
+// serve runs handler on a request with the JSON body, the route params and
+// the values the auth middleware would set, given as key/value pairs.
+func serve(handler gin.HandlerFunc, body string, params gin.Params, keys ...string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	c.Request = httptest.NewRequest("POST", "/", strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = params
+	for i := 0; i+1 < len(keys); i += 2 {
+		c.Set(keys[i], keys[i+1])
+	}
+	handler(c)
+	return recorder
+}
@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aalsa/management_dashboard/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+type HolidayHandler struct {
+	db *sqlx.DB
+}
+
+func NewHolidayHandler(db *sqlx.DB) *HolidayHandler {
+	return &HolidayHandler{db: db}
+}
+
+type HolidayCalendarRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+type HolidayRequest struct {
+	Date string `json:"date" binding:"required"`
+	Name string `json:"name" binding:"required,max=255"`
+}
+
+type HolidayCalendarDetail struct {
+	models.HolidayCalendar
+	Holidays []models.Holiday `json:"holidays"`
+}
+
+func (h *HolidayHandler) GetAll(c *gin.Context) {
+	calendars := []models.HolidayCalendar{}
+	if err := h.db.Select(&calendars, `SELECT * FROM holiday_calendars ORDER BY name`); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, calendars)
+}
+
+// GetByID returns the calendar with its holidays, limited to ?year= when
+// given.
+func (h *HolidayHandler) GetByID(c *gin.Context) {
+	var calendar HolidayCalendarDetail
+	if err := h.db.Get(&calendar.HolidayCalendar, `SELECT * FROM holiday_calendars WHERE id = $1`, c.Param("id")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Holiday calendar not found"})
+		return
+	}
+
+	args := []interface{}{calendar.ID}
+	filter := ""
+	if value := c.Query("year"); value != "" {
+		year, err := time.Parse("2006", value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "year must be a four-digit year"})
+			return
+		}
+		args = append(args, year.Year())
+		filter = " AND EXTRACT(YEAR FROM date) = $2"
+	}
+
+	calendar.Holidays = []models.Holiday{}
+	query := `SELECT * FROM holidays WHERE calendar_id = $1` + filter + ` ORDER BY date`
+	if err := h.db.Select(&calendar.Holidays, query, args...); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, calendar)
+}
+
+func (h *HolidayHandler) Create(c *gin.Context) {
+	var req HolidayCalendarRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if status, problem, err := h.check("", req); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	} else if problem != "" {
+		c.JSON(status, gin.H{"error": problem})
+		return
+	}
+
+	var calendar models.HolidayCalendar
+	query := `INSERT INTO holiday_calendars (name) VALUES ($1) RETURNING *`
+	if err := h.db.Get(&calendar, query, strings.TrimSpace(req.Name)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, calendar)
+}
+
+func (h *HolidayHandler) Update(c *gin.Context) {
+	id := c.Param("id")
+	var req HolidayCalendarRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if status, problem, err := h.check(id, req); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	} else if problem != "" {
+		c.JSON(status, gin.H{"error": problem})
+		return
+	}
+
+	query := `UPDATE holiday_calendars SET name = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`
+	result, err := h.db.Exec(query, strings.TrimSpace(req.Name), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Holiday calendar not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Holiday calendar updated"})
+}
+
+// Delete removes the calendar and its holidays; departments using it are
+// left without one.
+func (h *HolidayHandler) Delete(c *gin.Context) {
+	result, err := h.db.Exec(`DELETE FROM holiday_calendars WHERE id = $1`, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Holiday calendar not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Holiday calendar deleted"})
+}
+
+// AddHoliday adds a holiday to the calendar, renaming it when the date is
+// already a holiday.
+func (h *HolidayHandler) AddHoliday(c *gin.Context) {
+	id := c.Param("id")
+	var req HolidayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := time.Parse("2006-01-02", req.Date); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "date must be a date in YYYY-MM-DD format"})
+		return
+	}
+
+	var exists bool
+	if err := h.db.Get(&exists, `SELECT EXISTS (SELECT 1 FROM holiday_calendars WHERE id = $1)`, id); err != nil || !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Holiday calendar not found"})
+		return
+	}
+
+	var holiday models.Holiday
+	query := `INSERT INTO holidays (calendar_id, date, name) VALUES ($1, $2, $3)
+	          ON CONFLICT (calendar_id, date) DO UPDATE SET name = EXCLUDED.name
+	          RETURNING *`
+	if err := h.db.Get(&holiday, query, id, req.Date, strings.TrimSpace(req.Name)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, holiday)
+}
+
+func (h *HolidayHandler) RemoveHoliday(c *gin.Context) {
+	query := `DELETE FROM holidays WHERE calendar_id = $1 AND id = $2`
+	result, err := h.db.Exec(query, c.Param("id"), c.Param("holidayId"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Holiday not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Holiday removed"})
+}
+
+// check rejects blank or duplicate names, ignoring case. id is empty for new
+// calendars.
+func (h *HolidayHandler) check(id string, req HolidayCalendarRequest) (int, string, error) {
+	if strings.TrimSpace(req.Name) == "" {
+		return http.StatusBadRequest, "name is required", nil
+	}
+
+	var taken bool
+	query := `SELECT EXISTS (SELECT 1 FROM holiday_calendars WHERE lower(name) = lower($1) AND id::text <> $2)`
+	if err := h.db.Get(&taken, query, strings.TrimSpace(req.Name), id); err != nil {
+		return 0, "", err
+	}
+	if taken {
+		return http.StatusConflict, "A holiday calendar with this name already exists", nil
+	}
+	return 0, "", nil
+}
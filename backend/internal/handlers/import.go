@@ -0,0 +1,450 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/aalsa/management_dashboard/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	maxImportBytes = 10 << 20
+	maxImportRows  = 5000
+)
+
+type ImportRowResult struct {
+	Row    int    `json:"row"`
+	Action string `json:"action"`
+	ID     string `json:"id"`
+}
+
+type ImportError struct {
+	Row   int    `json:"row"`
+	Error string `json:"error"`
+}
+
+type ImportResponse struct {
+	DryRun    bool              `json:"dry_run"`
+	Committed bool              `json:"committed"`
+	Created   int               `json:"created"`
+	Updated   int               `json:"updated"`
+	Rows      []ImportRowResult `json:"rows"`
+	Errors    []ImportError     `json:"errors"`
+}
+
+// csvRow is one record of an uploaded CSV keyed by its lower-cased header.
+// Like listQuery, the typed getters keep the first parse error in err.
+type csvRow struct {
+	values map[string]string
+	err    error
+}
+
+func (r *csvRow) text(column string) string {
+	return strings.TrimSpace(r.values[column])
+}
+
+// has reports whether the file has the column at all, as opposed to a blank
+// cell.
+func (r *csvRow) has(column string) bool {
+	_, ok := r.values[column]
+	return ok
+}
+
+func (r *csvRow) optional(column string) *string {
+	if value := r.text(column); value != "" {
+		return &value
+	}
+	return nil
+}
+
+func (r *csvRow) optionalFloat(column string) *float64 {
+	value := r.text(column)
+	if value == "" {
+		return nil
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		r.fail(fmt.Errorf("%s must be a number", column))
+		return nil
+	}
+	return &f
+}
+
+func (r *csvRow) optionalInt(column string) *int {
+	value := r.text(column)
+	if value == "" {
+		return nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		r.fail(fmt.Errorf("%s must be a whole number", column))
+		return nil
+	}
+	return &n
+}
+
+func (r *csvRow) fail(err error) {
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+// importApply creates or updates the record for one row inside tx. It
+// returns "created" or "updated" with the record's id, or a problem that
+// rejects the row.
+type importApply func(tx *sqlx.Tx, row *csvRow) (action, id, problem string, err error)
+
+// runImport reads the CSV upload, given as a multipart "file" field or as the
+// raw request body, and applies each row in one transaction. Every row runs
+// under a savepoint so one bad row does not hide errors in the rows after
+// it. Nothing is committed if any row fails or ?dry_run=true is passed.
+// Rows that move records in a hierarchy lock its table, so lockTable, if
+// given, is locked up front: taking the lock after writing rows could
+// deadlock with a concurrent import.
+func runImport(c *gin.Context, db *sqlx.DB, lockTable string, apply importApply) {
+	dryRun := false
+	if value := c.Query("dry_run"); value != "" {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "dry_run must be true or false"})
+			return
+		}
+		dryRun = b
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBytes)
+	var body io.Reader = c.Request.Body
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		header, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Upload the CSV as the file field"})
+			return
+		}
+		file, err := header.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+
+	reader := csv.NewReader(body)
+	reader.TrimLeadingSpace = true
+	columns, err := reader.Read()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The CSV must start with a header row"})
+		return
+	}
+	for i, column := range columns {
+		columns[i] = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))
+	}
+
+	tx, err := db.Beginx()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer tx.Rollback()
+
+	if lockTable != "" {
+		if _, err := tx.Exec(`LOCK TABLE ` + lockTable + ` IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	response := ImportResponse{DryRun: dryRun, Rows: []ImportRowResult{}, Errors: []ImportError{}}
+	for count := 1; ; count++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			response.Errors = append(response.Errors, ImportError{Row: parseErr.StartLine, Error: parseErr.Err.Error()})
+			continue
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if count > maxImportRows {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Imports are limited to %d rows", maxImportRows)})
+			return
+		}
+
+		line, _ := reader.FieldPos(0)
+		row := &csvRow{values: map[string]string{}}
+		for i, column := range columns {
+			row.values[column] = record[i]
+		}
+
+		if _, err := tx.Exec("SAVEPOINT import_row"); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		action, id, problem, err := apply(tx, row)
+		if err != nil {
+			problem = err.Error()
+		}
+		if problem != "" {
+			response.Errors = append(response.Errors, ImportError{Row: line, Error: problem})
+			if _, err := tx.Exec("ROLLBACK TO SAVEPOINT import_row"); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			continue
+		}
+		if _, err := tx.Exec("RELEASE SAVEPOINT import_row"); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		response.Rows = append(response.Rows, ImportRowResult{Row: line, Action: action, ID: id})
+		if action == "created" {
+			response.Created++
+		} else {
+			response.Updated++
+		}
+	}
+
+	if len(response.Errors) > 0 {
+		c.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	if !dryRun {
+		if err := tx.Commit(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		response.Committed = true
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// lookupID resolves a row's reference to another record, given either as an
+// id column or as a natural key column, e.g. project_id or project (name).
+// It returns nil when both are empty.
+func lookupID(tx *sqlx.Tx, row *csvRow, idColumn, keyColumn, query, label string) (*string, string, error) {
+	if id := row.optional(idColumn); id != nil {
+		return id, "", nil
+	}
+	key := row.text(keyColumn)
+	if key == "" {
+		return nil, "", nil
+	}
+
+	var ids []string
+	if err := tx.Select(&ids, query, key); err != nil {
+		return nil, "", err
+	}
+	switch len(ids) {
+	case 0:
+		return nil, fmt.Sprintf("%s %q not found", label, key), nil
+	case 1:
+		return &ids[0], "", nil
+	}
+	return nil, fmt.Sprintf("%s %q is ambiguous; use %s", label, key, idColumn), nil
+}
+
+// Import creates or updates employees from a CSV with the columns email,
+// full_name, role, department_id or department (by name), hire_date, status,
+// weekly_capacity_hours and manager_id or manager_email. Rows are matched to
+// existing employees by email; a manager given by email must come before
+// their reports.
+func (h *EmployeeHandler) Import(c *gin.Context) {
+	runImport(c, h.db, "employees", func(tx *sqlx.Tx, row *csvRow) (string, string, string, error) {
+		employee := models.Employee{
+			Email:               row.text("email"),
+			FullName:            row.text("full_name"),
+			Role:                row.text("role"),
+			DepartmentID:        row.text("department_id"),
+			Department:          row.text("department"),
+			HireDate:            row.text("hire_date"),
+			Status:              row.text("status"),
+			WeeklyCapacityHours: row.optionalFloat("weekly_capacity_hours"),
+		}
+		if row.err != nil {
+			return "", "", row.err.Error(), nil
+		}
+		if problem := validateEmployee(employee); problem != "" {
+			return "", "", problem, nil
+		}
+		if problem, err := resolveDepartment(tx, &employee); err != nil || problem != "" {
+			return "", "", problem, err
+		}
+
+		managerID, problem, err := lookupID(tx, row, "manager_id", "manager_email",
+			`SELECT id FROM employees WHERE lower(email) = lower($1)`, "Manager")
+		if err != nil || problem != "" {
+			return "", "", problem, err
+		}
+		employee.ManagerID = managerID
+
+		var ids []string
+		if err := tx.Select(&ids, `SELECT id FROM employees WHERE lower(email) = lower($1)`, employee.Email); err != nil {
+			return "", "", "", err
+		}
+
+		if len(ids) > 0 {
+			if !canAccessEmployee(c, tx, ids[0]) || !canAccessDepartment(c, tx, employee.DepartmentID) {
+				return "", "", "You can only manage employees in your department", nil
+			}
+			// Files without a manager column keep the current manager.
+			if !row.has("manager_id") && !row.has("manager_email") {
+				if err := tx.Get(&employee.ManagerID, `SELECT manager_id FROM employees WHERE id = $1`, ids[0]); err != nil {
+					return "", "", "", err
+				}
+			}
+			if problem, err := lockAndCheckManager(tx, ids[0], employee.ManagerID); err != nil || problem != "" {
+				return "", "", problem, err
+			}
+			_, err := updateEmployee(tx, ids[0], employee)
+			return "updated", ids[0], "", err
+		}
+
+		if !canAccessDepartment(c, tx, employee.DepartmentID) {
+			return "", "", "You can only manage employees in your department", nil
+		}
+		if problem, err := checkManager(tx, "", employee.ManagerID); err != nil || problem != "" {
+			return "", "", problem, err
+		}
+		err = insertEmployee(tx, &employee)
+		return "created", employee.ID, "", err
+	})
+}
+
+// Import creates or updates projects from a CSV with the columns name,
+// description, start_date, end_date, status, budget and client_id or client
+// (by name). Rows are matched to existing projects by name.
+func (h *ProjectHandler) Import(c *gin.Context) {
+	runImport(c, h.db, "", func(tx *sqlx.Tx, row *csvRow) (string, string, string, error) {
+		project := models.Project{
+			Name:        row.text("name"),
+			Description: row.text("description"),
+			StartDate:   row.text("start_date"),
+			EndDate:     row.optional("end_date"),
+			Status:      row.text("status"),
+			Budget:      row.optionalFloat("budget"),
+		}
+		if row.err != nil {
+			return "", "", row.err.Error(), nil
+		}
+
+		clientID, problem, err := lookupID(tx, row, "client_id", "client",
+			`SELECT id FROM clients WHERE lower(name) = lower($1)`, "Client")
+		if err != nil || problem != "" {
+			return "", "", problem, err
+		}
+		project.ClientID = clientID
+
+		if problem, err := checkProject(tx, project); err != nil || problem != "" {
+			return "", "", problem, err
+		}
+
+		var ids []string
+		if err := tx.Select(&ids, `SELECT id FROM projects WHERE lower(name) = lower($1)`, project.Name); err != nil {
+			return "", "", "", err
+		}
+
+		switch len(ids) {
+		case 0:
+			err := insertProject(tx, &project)
+			return "created", project.ID, "", err
+		case 1:
+			// Files without a client column keep the current client.
+			if !row.has("client_id") && !row.has("client") {
+				if err := tx.Get(&project.ClientID, `SELECT client_id FROM projects WHERE id = $1`, ids[0]); err != nil {
+					return "", "", "", err
+				}
+			}
+			_, err := updateProject(tx, ids[0], project)
+			return "updated", ids[0], "", err
+		}
+		return "", "", fmt.Sprintf("Several projects are named %q", project.Name), nil
+	})
+}
+
+// Import creates or updates tasks from a CSV with the columns title,
+// description, project_id or project (by name), assigned_to or
+// assignee_email, parent_id, status, priority, due_date, story_points and
+// estimated_hours. Rows are matched to existing tasks by title within the
+// project.
+func (h *TaskHandler) Import(c *gin.Context) {
+	runImport(c, h.db, "tasks", func(tx *sqlx.Tx, row *csvRow) (string, string, string, error) {
+		task := models.Task{
+			Title:          row.text("title"),
+			Description:    row.text("description"),
+			ParentID:       row.optional("parent_id"),
+			Status:         row.text("status"),
+			Priority:       row.text("priority"),
+			DueDate:        row.optional("due_date"),
+			StoryPoints:    row.optionalInt("story_points"),
+			EstimatedHours: row.optionalFloat("estimated_hours"),
+		}
+		if row.err != nil {
+			return "", "", row.err.Error(), nil
+		}
+
+		projectID, problem, err := lookupID(tx, row, "project_id", "project",
+			`SELECT id FROM projects WHERE lower(name) = lower($1)`, "Project")
+		if err != nil || problem != "" {
+			return "", "", problem, err
+		}
+		if projectID == nil {
+			return "", "", "project_id or project is required", nil
+		}
+		if _, err := uuid.Parse(*projectID); err != nil {
+			return "", "", "project_id must be a valid id", nil
+		}
+		task.ProjectID = *projectID
+
+		task.AssignedTo, problem, err = lookupID(tx, row, "assigned_to", "assignee_email",
+			`SELECT id FROM employees WHERE lower(email) = lower($1)`, "Employee")
+		if err != nil || problem != "" {
+			return "", "", problem, err
+		}
+
+		var matches []models.Task
+		query := `SELECT * FROM tasks WHERE project_id = $1 AND lower(title) = lower($2)`
+		if err := tx.Select(&matches, query, task.ProjectID, task.Title); err != nil {
+			return "", "", "", err
+		}
+		if len(matches) > 1 {
+			return "", "", fmt.Sprintf("Several tasks in the project are titled %q", task.Title), nil
+		}
+
+		if len(matches) == 1 {
+			existing := matches[0]
+			task.ID = existing.ID
+			if !row.has("parent_id") {
+				task.ParentID = existing.ParentID
+			}
+			if status, message, err := checkTask(c, tx, &existing, task); err != nil || status != 0 {
+				return "", "", message, err
+			}
+			_, err := updateTask(tx, c.GetString("userID"), existing, task)
+			return "updated", task.ID, "", err
+		}
+
+		task.ID = uuid.New().String()
+		if status, message, err := checkTask(c, tx, nil, task); err != nil || status != 0 {
+			return "", "", message, err
+		}
+		err = insertTask(tx, c.GetString("userID"), &task)
+		return "created", task.ID, "", err
+	})
+}
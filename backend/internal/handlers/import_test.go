@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/aalsa/management_dashboard/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestCSVRow(t *testing.T) {
+	row := &csvRow{values: map[string]string{
+		"manager_email": "",
+		"name":          "  Website  ",
+		"budget":        "1500.5",
+		"hours":         "abc",
+		"story_points":  "3",
+	}}
+
+	tests := []struct {
+		name string
+		got  interface{}
+		want interface{}
+	}{
+		{"has blank column", row.has("manager_email"), true},
+		{"has missing column", row.has("manager_id"), false},
+		{"text is trimmed", row.text("name"), "Website"},
+		{"text of missing column", row.text("client"), ""},
+		{"optional blank", row.optional("manager_email") == nil, true},
+		{"optional set", *row.optional("name"), "Website"},
+		{"float", *row.optionalFloat("budget"), 1500.5},
+		{"int", *row.optionalInt("story_points"), 3},
+		{"bad float", row.optionalFloat("hours") == nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+
+	if row.err == nil || row.err.Error() != "hours must be a number" {
+		t.Errorf("err = %v, want the first parse error", row.err)
+	}
+	row.optionalInt("name")
+	if row.err.Error() != "hours must be a number" {
+		t.Errorf("err = %v, want the first parse error kept", row.err)
+	}
+}
+
+func TestImportLocksBeforeRows(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler func(db *sqlx.DB) gin.HandlerFunc
+		body    string
+		steps   []fakeStep
+	}{
+		{"tasks", func(db *sqlx.DB) gin.HandlerFunc { return NewTaskHandler(db).Import },
+			"title,project\nShip,\n", []fakeStep{{match: "LOCK TABLE tasks"}}},
+		{"employees", func(db *sqlx.DB) gin.HandlerFunc { return NewEmployeeHandler(db).Import },
+			"email,full_name\nnot-an-email,Ada\n", []fakeStep{{match: "LOCK TABLE employees"}}},
+		{"projects have no hierarchy", func(db *sqlx.DB) gin.HandlerFunc { return NewProjectHandler(db).Import },
+			"name\n\"\"\n", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			steps := append(tt.steps, fakeStep{match: "SAVEPOINT import_row"}, fakeStep{match: "ROLLBACK TO SAVEPOINT"})
+			db, _ := newFakeDB(t, steps...)
+
+			w := serve(tt.handler(db), tt.body, nil, "role", models.RoleAdmin)
+			if w.Code != http.StatusUnprocessableEntity {
+				t.Errorf("Import() = %d %s, want %d", w.Code, w.Body, http.StatusUnprocessableEntity)
+			}
+		})
+	}
+}
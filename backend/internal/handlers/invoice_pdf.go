@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aalsa/management_dashboard/internal/pdf"
+)
+
+const (
+	invoiceMargin     = 50.0
+	invoiceRowHeight  = 18.0
+	invoicePageBottom = pdf.PageHeight - 80
+)
+
+// renderInvoice lays out the invoice on A4 pages: a header with the invoice
+// and client details, then the line items, continuing on new pages as needed.
+func renderInvoice(invoice InvoiceDetail) *pdf.Document {
+	doc := pdf.New()
+	right := pdf.PageWidth - invoiceMargin
+
+	doc.Text(invoiceMargin, 70, 22, true, "INVOICE")
+	doc.TextRight(right, 70, 12, true, invoice.Number)
+	doc.TextRight(right, 88, 10, false, "Status: "+invoice.Status)
+	doc.TextRight(right, 102, 10, false, fmt.Sprintf("Period: %s to %s", dateOnly(invoice.PeriodStart), dateOnly(invoice.PeriodEnd)))
+	if invoice.IssuedAt != nil {
+		doc.TextRight(right, 116, 10, false, "Issued: "+invoice.IssuedAt.Format("2006-01-02"))
+	}
+
+	y := 130.0
+	doc.Text(invoiceMargin, y, 10, true, "Bill to")
+	y += 16
+	doc.Text(invoiceMargin, y, 11, false, invoice.Client.Name)
+	if invoice.Client.Email != nil && *invoice.Client.Email != "" {
+		y += 14
+		doc.Text(invoiceMargin, y, 10, false, *invoice.Client.Email)
+	}
+	if invoice.Client.Address != nil {
+		for _, line := range strings.Split(strings.ReplaceAll(*invoice.Client.Address, "\r\n", "\n"), "\n") {
+			y += 14
+			doc.Text(invoiceMargin, y, 10, false, line)
+		}
+	}
+
+	y += 40
+	header := func() {
+		doc.Text(invoiceMargin, y, 10, true, "Description")
+		doc.TextRight(right-160, y, 10, true, "Hours")
+		doc.TextRight(right-80, y, 10, true, "Rate")
+		doc.TextRight(right, y, 10, true, "Amount")
+		doc.Line(invoiceMargin, y+6, right, y+6)
+		y += invoiceRowHeight + 4
+	}
+	header()
+
+	for _, item := range invoice.LineItems {
+		if y > invoicePageBottom {
+			doc.AddPage()
+			y = 70
+			header()
+		}
+		doc.Text(invoiceMargin, y, 10, false, truncate(item.Description, right-220-invoiceMargin, 10))
+		doc.TextRight(right-160, y, 10, false, fmt.Sprintf("%.2f", item.Hours))
+		doc.TextRight(right-80, y, 10, false, fmt.Sprintf("%.2f", item.Rate))
+		doc.TextRight(right, y, 10, false, fmt.Sprintf("%.2f", item.Amount))
+		y += invoiceRowHeight
+	}
+
+	doc.Line(right-200, y-8, right, y-8)
+	y += 6
+	doc.TextRight(right-80, y, 11, true, "Total")
+	doc.TextRight(right, y, 11, true, fmt.Sprintf("%.2f", invoice.Total))
+
+	return doc
+}
+
+// dateOnly trims the time from a DATE column scanned as an RFC 3339 string.
+func dateOnly(date string) string {
+	if len(date) > 10 {
+		return date[:10]
+	}
+	return date
+}
+
+// truncate shortens s with an ellipsis so it fits in width points.
+func truncate(s string, width, size float64) string {
+	if pdf.Width(s, size) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && pdf.Width(string(runes)+"...", size) > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "..."
+}
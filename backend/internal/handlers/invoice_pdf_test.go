@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/aalsa/management_dashboard/internal/pdf"
+)
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name  string
+		s     string
+		width float64
+		want  string
+	}{
+		{"fits", "Design review", 100, "Design review"},
+		{"exact fit", "Design review", pdf.Width("Design review", 10), "Design review"},
+		{"shortened", "Consulting hours for March", 60, "Consulting ..."},
+		{"accented", "Développement", 50, "Dévelop..."},
+		{"only the ellipsis fits", "Consulting hours for March", 5, "..."},
+		{"empty", "", 0, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := truncate(tt.s, tt.width, 10)
+			if got != tt.want {
+				t.Errorf("truncate(%q, %v) = %q, want %q", tt.s, tt.width, got, tt.want)
+			}
+			if got != "..." && pdf.Width(got, 10) > tt.width {
+				t.Errorf("truncate(%q, %v) = %q is %v wide", tt.s, tt.width, got, pdf.Width(got, 10))
+			}
+		})
+	}
+}
@@ -0,0 +1,312 @@
+package handlers
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/aalsa/management_dashboard/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const invoicedMessage = "This time log has been invoiced and can no longer be changed"
+
+type InvoiceHandler struct {
+	db *sqlx.DB
+}
+
+func NewInvoiceHandler(db *sqlx.DB) *InvoiceHandler {
+	return &InvoiceHandler{db: db}
+}
+
+type CreateInvoiceRequest struct {
+	ClientID    string `json:"client_id" binding:"required"`
+	PeriodStart string `json:"period_start" binding:"required"`
+	PeriodEnd   string `json:"period_end" binding:"required"`
+}
+
+type InvoiceDetail struct {
+	models.Invoice
+	Client    models.Client            `json:"client"`
+	LineItems []models.InvoiceLineItem `json:"line_items"`
+}
+
+var invoiceSortFields = map[string]string{
+	"number":       "number",
+	"period_start": "period_start",
+	"total":        "total",
+	"status":       "status",
+	"created_at":   "created_at",
+}
+
+func (h *InvoiceHandler) GetAll(c *gin.Context) {
+	q := newListQuery(c, invoiceSortFields, "created_at DESC")
+	q.id("client_id", "client_id = ?")
+	q.oneOf("status", "status")
+	q.date("from", "period_end >= ?")
+	q.date("to", "period_start <= ?")
+
+	invoices := []models.Invoice{}
+	listPage(c, h.db, &invoices, "invoices", q)
+}
+
+func (h *InvoiceHandler) GetByID(c *gin.Context) {
+	detail, ok := h.load(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, detail)
+}
+
+func (h *InvoiceHandler) GetPDF(c *gin.Context) {
+	detail, ok := h.load(c)
+	if !ok {
+		return
+	}
+
+	var buf bytes.Buffer
+	if _, err := renderInvoice(detail).WriteTo(&buf); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", `inline; filename="`+detail.Number+`.pdf"`)
+	c.Data(http.StatusOK, "application/pdf", buf.Bytes())
+}
+
+// Create bills the client's uninvoiced, billable time logs in the period at
+// the client's hourly rate, with one line item per task. The logs are locked
+// and stamped with the invoice in the same transaction, so concurrent
+// invoices cannot bill them twice.
+func (h *InvoiceHandler) Create(c *gin.Context) {
+	var req CreateInvoiceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	start, err := time.Parse("2006-01-02", req.PeriodStart)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "period_start must be a date in YYYY-MM-DD format"})
+		return
+	}
+	end, err := time.Parse("2006-01-02", req.PeriodEnd)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "period_end must be a date in YYYY-MM-DD format"})
+		return
+	}
+	if end.Before(start) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "period_end must not be before period_start"})
+		return
+	}
+
+	var client models.Client
+	if err := h.db.Get(&client, `SELECT * FROM clients WHERE id = $1`, req.ClientID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Client not found"})
+		return
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer tx.Rollback()
+
+	var logIDs []string
+	query := `SELECT l.id FROM time_logs l
+	          JOIN tasks t ON t.id = l.task_id
+	          JOIN projects p ON p.id = t.project_id
+	          WHERE p.client_id = $1 AND l.billable AND l.invoice_id IS NULL
+	          AND l.log_date BETWEEN $2 AND $3
+	          FOR UPDATE OF l`
+	if err := tx.Select(&logIDs, query, client.ID, req.PeriodStart, req.PeriodEnd); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if len(logIDs) == 0 {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "No uninvoiced billable time for this client in the period"})
+		return
+	}
+
+	var invoiceID string
+	query = `INSERT INTO invoices (client_id, period_start, period_end, created_by)
+	         VALUES ($1, $2, $3, $4) RETURNING id`
+	if err := tx.Get(&invoiceID, query, client.ID, req.PeriodStart, req.PeriodEnd, c.GetString("userID")); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	query = `INSERT INTO invoice_line_items (invoice_id, task_id, description, hours, rate, amount)
+	         SELECT $1::uuid, t.id, p.name || ': ' || t.title, SUM(l.hours), $3::numeric, ROUND(SUM(l.hours) * $3::numeric, 2)
+	         FROM time_logs l
+	         JOIN tasks t ON t.id = l.task_id
+	         JOIN projects p ON p.id = t.project_id
+	         WHERE l.id = ANY($2::uuid[])
+	         GROUP BY t.id, p.name, t.title
+	         ORDER BY p.name, t.title`
+	if _, err := tx.Exec(query, invoiceID, pq.Array(logIDs), client.HourlyRate); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := tx.Exec(`UPDATE time_logs SET invoice_id = $1 WHERE id = ANY($2::uuid[])`, invoiceID, pq.Array(logIDs)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	query = `UPDATE invoices SET total = (SELECT SUM(amount) FROM invoice_line_items WHERE invoice_id = $1)
+	         WHERE id = $1`
+	if _, err := tx.Exec(query, invoiceID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	detail, err := loadInvoice(h.db, invoiceID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, detail)
+}
+
+func (h *InvoiceHandler) Issue(c *gin.Context) {
+	query := `UPDATE invoices SET status = 'issued', issued_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+	          WHERE id = $1 AND status = 'draft'`
+	h.transition(c, "Only draft invoices can be issued", query)
+}
+
+func (h *InvoiceHandler) MarkPaid(c *gin.Context) {
+	query := `UPDATE invoices SET status = 'paid', paid_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+	          WHERE id = $1 AND status = 'issued'`
+	h.transition(c, "Only issued invoices can be marked as paid", query)
+}
+
+// Void cancels an unpaid invoice and releases its time logs so they can be
+// billed again. The line items are kept as a record.
+func (h *InvoiceHandler) Void(c *gin.Context) {
+	query := `UPDATE invoices SET status = 'void', updated_at = CURRENT_TIMESTAMP
+	          WHERE id = $1 AND status IN ('draft', 'issued')`
+	h.transition(c, "Only draft or issued invoices can be voided", query,
+		`UPDATE time_logs SET invoice_id = NULL WHERE invoice_id = $1`)
+}
+
+// Delete removes a draft invoice; its time logs become uninvoiced again.
+func (h *InvoiceHandler) Delete(c *gin.Context) {
+	id := c.Param("id")
+
+	var status string
+	if err := h.db.Get(&status, `SELECT status FROM invoices WHERE id = $1`, id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invoice not found"})
+		return
+	}
+	if status != "draft" {
+		c.JSON(http.StatusConflict, gin.H{"error": "Only draft invoices can be deleted"})
+		return
+	}
+
+	result, err := h.db.Exec(`DELETE FROM invoices WHERE id = $1 AND status = 'draft'`, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Only draft invoices can be deleted"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invoice deleted"})
+}
+
+// transition runs query, and any follow-up statements, against the invoice
+// in :id inside one transaction and responds with the updated invoice.
+func (h *InvoiceHandler) transition(c *gin.Context, conflict, query string, then ...string) {
+	id := c.Param("id")
+
+	var exists bool
+	if err := h.db.Get(&exists, `SELECT EXISTS (SELECT 1 FROM invoices WHERE id = $1)`, id); err != nil || !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invoice not found"})
+		return
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(query, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": conflict})
+		return
+	}
+
+	for _, statement := range then {
+		if _, err := tx.Exec(statement, id); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	detail, err := loadInvoice(h.db, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, detail)
+}
+
+func (h *InvoiceHandler) load(c *gin.Context) (InvoiceDetail, bool) {
+	detail, err := loadInvoice(h.db, c.Param("id"))
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invoice not found"})
+		return detail, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return detail, false
+	}
+	return detail, true
+}
+
+func loadInvoice(db *sqlx.DB, id string) (InvoiceDetail, error) {
+	var detail InvoiceDetail
+	if err := db.Get(&detail.Invoice, `SELECT * FROM invoices WHERE id = $1`, id); err != nil {
+		return detail, err
+	}
+
+	if err := db.Get(&detail.Client, `SELECT * FROM clients WHERE id = $1`, detail.ClientID); err != nil {
+		return detail, err
+	}
+
+	detail.LineItems = []models.InvoiceLineItem{}
+	query := `SELECT * FROM invoice_line_items WHERE invoice_id = $1 ORDER BY description`
+	err := db.Select(&detail.LineItems, query, id)
+	return detail, err
+}
@@ -0,0 +1,629 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aalsa/management_dashboard/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+type LeaveHandler struct {
+	db *sqlx.DB
+}
+
+func NewLeaveHandler(db *sqlx.DB) *LeaveHandler {
+	return &LeaveHandler{db: db}
+}
+
+// leaveTypeOrder lists the leave types in the order balances are reported.
+var leaveTypeOrder = []string{"vacation", "sick", "other"}
+
+var leaveTypes = map[string]bool{"vacation": true, "sick": true, "other": true}
+
+// daysOffTable lists the weekdays each employee is unavailable: days of
+// approved leave and the holidays in their department's calendar. Capacity
+// is spread evenly over Monday to Friday, so each day off removes a fifth of
+// the employee's weekly capacity.
+const daysOffTable = `(SELECT l.employee_id, d::date AS day
+	FROM leave_requests l
+	CROSS JOIN generate_series(l.start_date, l.end_date, interval '1 day') d
+	WHERE l.status = 'approved' AND EXTRACT(ISODOW FROM d) < 6
+	UNION
+	SELECT e.id, h.date
+	FROM employees e
+	JOIN departments dp ON dp.id = e.department_id
+	JOIN holidays h ON h.calendar_id = dp.holiday_calendar_id
+	WHERE EXTRACT(ISODOW FROM h.date) < 6) AS days_off`
+
+// employeeHolidays selects the holiday dates of employee $1.
+const employeeHolidays = `(SELECT h.date FROM holidays h
+	JOIN departments dp ON dp.holiday_calendar_id = h.calendar_id
+	JOIN employees e ON e.department_id = dp.id
+	WHERE e.id = $1)`
+
+type LeaveRequestBody struct {
+	EmployeeID string `json:"employee_id"`
+	Type       string `json:"type" binding:"required"`
+	StartDate  string `json:"start_date" binding:"required"`
+	EndDate    string `json:"end_date" binding:"required"`
+	Reason     string `json:"reason"`
+}
+
+type RejectLeaveRequest struct {
+	Comment string `json:"comment" binding:"required"`
+}
+
+type LeaveBalanceRequest struct {
+	Year          int     `json:"year" binding:"required,min=2000,max=2100"`
+	Type          string  `json:"type" binding:"required"`
+	AllowanceDays float64 `json:"allowance_days" binding:"gte=0,lte=366"`
+}
+
+// LeaveBalance reports one leave type for a year. Types without an allowance
+// are unlimited and have no remaining days.
+type LeaveBalance struct {
+	Year          int      `json:"year"`
+	Type          string   `json:"type"`
+	AllowanceDays *float64 `json:"allowance_days"`
+	UsedDays      float64  `json:"used_days"`
+	PendingDays   float64  `json:"pending_days"`
+	RemainingDays *float64 `json:"remaining_days"`
+}
+
+// LeaveRequestResponse is a leave request with the open tasks of the
+// employee that are due during it.
+type LeaveRequestResponse struct {
+	models.LeaveRequest
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+var leaveSortFields = map[string]string{
+	"start_date": "start_date",
+	"status":     "status",
+	"created_at": "created_at",
+}
+
+// GetAll lists the leave of the employees the caller may see; see
+// scopeToEmployees.
+func (h *LeaveHandler) GetAll(c *gin.Context) {
+	q := newListQuery(c, leaveSortFields, "start_date DESC")
+	scopeToEmployees(c, q, "employee_id")
+	q.id("employee_id", "employee_id = ?")
+	q.oneOf("status", "status")
+	q.oneOf("type", "type")
+	q.date("from", "end_date >= ?")
+	q.date("to", "start_date <= ?")
+
+	requests := []models.LeaveRequest{}
+	listPage(c, h.db, &requests, "leave_requests", q)
+}
+
+func (h *LeaveHandler) GetByID(c *gin.Context) {
+	request, ok := h.load(c)
+	if !ok {
+		return
+	}
+
+	if !canViewLeave(c, h.db, request.EmployeeID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You cannot view leave for this employee"})
+		return
+	}
+
+	c.JSON(http.StatusOK, request)
+}
+
+// Create files a pending request for the caller's employee, or for
+// employee_id when the caller may manage that employee. Only working days
+// (weekdays that are not holidays) count, and leave with an allowance for
+// the year cannot exceed what is left of it.
+func (h *LeaveHandler) Create(c *gin.Context) {
+	var req LeaveRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !leaveTypes[req.Type] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "type must be one of vacation, sick, other"})
+		return
+	}
+	start, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "start_date must be a date in YYYY-MM-DD format"})
+		return
+	}
+	end, err := time.Parse("2006-01-02", req.EndDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "end_date must be a date in YYYY-MM-DD format"})
+		return
+	}
+	if end.Before(start) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "end_date cannot be before start_date"})
+		return
+	}
+
+	employeeID := req.EmployeeID
+	if employeeID == "" {
+		var ok bool
+		if employeeID, ok = currentEmployee(c); !ok {
+			return
+		}
+	}
+
+	var exists bool
+	if err := h.db.Get(&exists, `SELECT EXISTS (SELECT 1 FROM employees WHERE id::text = $1)`, employeeID); err != nil || !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Employee not found"})
+		return
+	}
+
+	if !canAccessEmployee(c, h.db, employeeID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You cannot request leave for this employee"})
+		return
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer tx.Rollback()
+
+	// Requests for the same employee are checked and saved one at a time so
+	// two of them cannot both fit the same remaining balance.
+	if _, err := tx.Exec(`SELECT id FROM employees WHERE id = $1 FOR UPDATE`, employeeID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var overlaps bool
+	query := `SELECT EXISTS (SELECT 1 FROM leave_requests
+	          WHERE employee_id = $1 AND status IN ('pending', 'approved')
+	          AND start_date <= $3 AND end_date >= $2)`
+	if err := tx.Get(&overlaps, query, employeeID, req.StartDate, req.EndDate); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if overlaps {
+		c.JSON(http.StatusConflict, gin.H{"error": "This leave overlaps another pending or approved request"})
+		return
+	}
+
+	days, err := workingDays(tx, employeeID, req.StartDate, req.EndDate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	total := 0.0
+	for _, n := range days {
+		total += n
+	}
+	if total == 0 {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "The requested dates contain no working days"})
+		return
+	}
+
+	if problem, err := checkLeaveBalance(tx, employeeID, req.Type, days, ""); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	} else if problem != "" {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": problem})
+		return
+	}
+
+	var response LeaveRequestResponse
+	query = `INSERT INTO leave_requests (employee_id, type, start_date, end_date, days, reason)
+	         VALUES ($1, $2, $3, $4, $5, $6) RETURNING *`
+	err = tx.Get(&response.LeaveRequest, query, employeeID, req.Type, req.StartDate, req.EndDate, total, req.Reason)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if response.Warnings, err = dueDuringLeave(tx, employeeID, req.StartDate, req.EndDate); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// Approve checks the balance again, since allowances may have changed, and
+// warns about open tasks due during the leave.
+func (h *LeaveHandler) Approve(c *gin.Context) {
+	request, ok := h.load(c)
+	if !ok {
+		return
+	}
+
+	if !canReview(c, h.db, request.EmployeeID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the employee's manager can review this leave request"})
+		return
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`SELECT id FROM employees WHERE id = $1 FOR UPDATE`, request.EmployeeID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	days, err := workingDays(tx, request.EmployeeID, request.StartDate, request.EndDate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	total := 0.0
+	for _, n := range days {
+		total += n
+	}
+	if problem, err := checkLeaveBalance(tx, request.EmployeeID, request.Type, days, request.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	} else if problem != "" {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": problem})
+		return
+	}
+
+	var response LeaveRequestResponse
+	// Holidays added since the request was filed change its length, so the
+	// days are stored as counted here.
+	query := `UPDATE leave_requests SET status = 'approved', reviewed_by = $2, reviewed_at = CURRENT_TIMESTAMP,
+	          review_comment = '', days = $3, updated_at = CURRENT_TIMESTAMP
+	          WHERE id = $1 AND status = 'pending'
+	          RETURNING *`
+	err = tx.Get(&response.LeaveRequest, query, request.ID, c.GetString("userID"), total)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Only pending leave requests can be approved"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if response.Warnings, err = dueDuringLeave(tx, request.EmployeeID, request.StartDate, request.EndDate); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Reject declines a pending request or revokes an approved one.
+func (h *LeaveHandler) Reject(c *gin.Context) {
+	request, ok := h.load(c)
+	if !ok {
+		return
+	}
+
+	var req RejectLeaveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !canReview(c, h.db, request.EmployeeID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the employee's manager can review this leave request"})
+		return
+	}
+
+	query := `UPDATE leave_requests SET status = 'rejected', reviewed_by = $2, reviewed_at = CURRENT_TIMESTAMP,
+	          review_comment = $3, updated_at = CURRENT_TIMESTAMP
+	          WHERE id = $1 AND status IN ('pending', 'approved')`
+	h.transition(c, "Only pending or approved leave requests can be rejected", "Leave request rejected",
+		query, request.ID, c.GetString("userID"), req.Comment)
+}
+
+// Cancel withdraws a pending request, or approved leave that has not started
+// yet. The employee and anyone who may manage them can cancel.
+func (h *LeaveHandler) Cancel(c *gin.Context) {
+	request, ok := h.load(c)
+	if !ok {
+		return
+	}
+
+	if !canAccessEmployee(c, h.db, request.EmployeeID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You cannot cancel leave for this employee"})
+		return
+	}
+
+	query := `UPDATE leave_requests SET status = 'cancelled', updated_at = CURRENT_TIMESTAMP
+	          WHERE id = $1 AND (status = 'pending' OR (status = 'approved' AND start_date > CURRENT_DATE))`
+	h.transition(c, "Only pending or future approved leave can be cancelled", "Leave request cancelled",
+		query, request.ID)
+}
+
+// GetBalances reports the employee's leave per type for ?year= (default the
+// current year).
+func (h *LeaveHandler) GetBalances(c *gin.Context) {
+	id := c.Param("id")
+
+	year := time.Now().Year()
+	if value := c.Query("year"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 2000 || n > 2100 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "year must be between 2000 and 2100"})
+			return
+		}
+		year = n
+	}
+
+	var exists bool
+	if err := h.db.Get(&exists, `SELECT EXISTS (SELECT 1 FROM employees WHERE id = $1)`, id); err != nil || !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Employee not found"})
+		return
+	}
+
+	if !canViewLeave(c, h.db, id) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You cannot view leave for this employee"})
+		return
+	}
+
+	balances, err := leaveBalances(h.db, id, year, "")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	respondReport(c, balances, csvSection{"balances", balances})
+}
+
+// SetBalance sets the employee's allowance for a leave type and year.
+func (h *LeaveHandler) SetBalance(c *gin.Context) {
+	id := c.Param("id")
+	var req LeaveBalanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !leaveTypes[req.Type] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "type must be one of vacation, sick, other"})
+		return
+	}
+
+	var exists bool
+	if err := h.db.Get(&exists, `SELECT EXISTS (SELECT 1 FROM employees WHERE id = $1)`, id); err != nil || !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Employee not found"})
+		return
+	}
+
+	if !canAccessEmployee(c, h.db, id) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only manage employees in your department"})
+		return
+	}
+
+	query := `INSERT INTO leave_balances (employee_id, year, type, allowance_days) VALUES ($1, $2, $3, $4)
+	          ON CONFLICT (employee_id, year, type)
+	          DO UPDATE SET allowance_days = EXCLUDED.allowance_days, updated_at = CURRENT_TIMESTAMP`
+	if _, err := h.db.Exec(query, id, req.Year, req.Type, req.AllowanceDays); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Leave balance saved"})
+}
+
+// canViewLeave reports whether the caller may see the employee's leave:
+// their own, or an employee they may manage.
+func canViewLeave(c *gin.Context, db sqlx.Queryer, employeeID string) bool {
+	if employeeID == c.GetString("employeeID") {
+		return true
+	}
+	return canAccessEmployee(c, db, employeeID)
+}
+
+func (h *LeaveHandler) load(c *gin.Context) (models.LeaveRequest, bool) {
+	var request models.LeaveRequest
+	if err := h.db.Get(&request, `SELECT * FROM leave_requests WHERE id = $1`, c.Param("id")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Leave request not found"})
+		return request, false
+	}
+	return request, true
+}
+
+func (h *LeaveHandler) transition(c *gin.Context, conflict, message, query string, args ...interface{}) {
+	result, err := h.db.Exec(query, args...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": conflict})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": message})
+}
+
+// workingDays counts, per year, the weekdays from start to end that are not
+// holidays for the employee.
+func workingDays(q sqlx.Queryer, employeeID, start, end string) (map[int]float64, error) {
+	var rows []struct {
+		Year int     `db:"year"`
+		Days float64 `db:"days"`
+	}
+	query := `SELECT EXTRACT(YEAR FROM d)::int AS year, COUNT(*) AS days
+	          FROM generate_series($2::date, $3::date, interval '1 day') d
+	          WHERE EXTRACT(ISODOW FROM d) < 6 AND d::date NOT IN ` + employeeHolidays + `
+	          GROUP BY 1`
+	if err := sqlx.Select(q, &rows, query, employeeID, start, end); err != nil {
+		return nil, err
+	}
+
+	days := map[int]float64{}
+	for _, row := range rows {
+		days[row.Year] = row.Days
+	}
+	return days, nil
+}
+
+// leaveBalances reports the employee's allowance and the working days of
+// approved and pending leave in the year for every leave type. The request
+// exceptID, if any, is left out.
+func leaveBalances(q sqlx.Queryer, employeeID string, year int, exceptID string) ([]LeaveBalance, error) {
+	var allowances []struct {
+		Type          string  `db:"type"`
+		AllowanceDays float64 `db:"allowance_days"`
+	}
+	query := `SELECT type, allowance_days FROM leave_balances WHERE employee_id = $1 AND year = $2`
+	if err := sqlx.Select(q, &allowances, query, employeeID, year); err != nil {
+		return nil, err
+	}
+
+	var usage []struct {
+		Type    string  `db:"type"`
+		Used    float64 `db:"used"`
+		Pending float64 `db:"pending"`
+	}
+	query = `SELECT l.type,
+	                COUNT(*) FILTER (WHERE l.status = 'approved') AS used,
+	                COUNT(*) FILTER (WHERE l.status = 'pending') AS pending
+	         FROM leave_requests l
+	         CROSS JOIN generate_series(l.start_date, l.end_date, interval '1 day') d
+	         WHERE l.employee_id = $1 AND l.status IN ('pending', 'approved') AND l.id::text <> $3
+	           AND EXTRACT(YEAR FROM d) = $2 AND EXTRACT(ISODOW FROM d) < 6
+	           AND d::date NOT IN ` + employeeHolidays + `
+	         GROUP BY 1`
+	if err := sqlx.Select(q, &usage, query, employeeID, year, exceptID); err != nil {
+		return nil, err
+	}
+
+	balances := make([]LeaveBalance, len(leaveTypeOrder))
+	for i, leaveType := range leaveTypeOrder {
+		balance := &balances[i]
+		balance.Year = year
+		balance.Type = leaveType
+		for _, u := range usage {
+			if u.Type == leaveType {
+				balance.UsedDays, balance.PendingDays = u.Used, u.Pending
+			}
+		}
+		for _, a := range allowances {
+			if a.Type == leaveType {
+				allowance := a.AllowanceDays
+				remaining := allowance - balance.UsedDays - balance.PendingDays
+				balance.AllowanceDays, balance.RemainingDays = &allowance, &remaining
+			}
+		}
+	}
+	return balances, nil
+}
+
+// checkLeaveBalance reports a problem when the working days per year of a
+// request exceed what is left of an allowance. Pending requests other than
+// exceptID reserve their days.
+func checkLeaveBalance(q sqlx.Queryer, employeeID, leaveType string, days map[int]float64, exceptID string) (string, error) {
+	for year, requested := range days {
+		balances, err := leaveBalances(q, employeeID, year, exceptID)
+		if err != nil {
+			return "", err
+		}
+		if problem := balanceProblem(balances, leaveType, year, requested); problem != "" {
+			return problem, nil
+		}
+	}
+	return "", nil
+}
+
+// balanceProblem describes why the year's balances cannot cover the requested
+// days of leaveType, or returns "" when they can.
+func balanceProblem(balances []LeaveBalance, leaveType string, year int, requested float64) string {
+	for _, balance := range balances {
+		if balance.Type == leaveType && balance.RemainingDays != nil && requested > *balance.RemainingDays {
+			return fmt.Sprintf("Not enough %s balance for %d: %g days left, %g requested",
+				leaveType, year, *balance.RemainingDays, requested)
+		}
+	}
+	return ""
+}
+
+// dueDuringLeave describes the employee's open tasks due between start and
+// end.
+func dueDuringLeave(q sqlx.Queryer, employeeID, start, end string) ([]string, error) {
+	var tasks []struct {
+		Title   string `db:"title"`
+		DueDate string `db:"due_date"`
+	}
+	query := `SELECT title, to_char(due_date, 'YYYY-MM-DD') AS due_date FROM tasks
+	          WHERE assigned_to = $1 AND completed_at IS NULL AND due_date BETWEEN $2::date AND $3::date
+	          ORDER BY due_date, title`
+	if err := sqlx.Select(q, &tasks, query, employeeID, start, end); err != nil {
+		return nil, err
+	}
+
+	var warnings []string
+	for _, task := range tasks {
+		warnings = append(warnings, fmt.Sprintf("Task %q is due on %s, during this leave", task.Title, task.DueDate))
+	}
+	return warnings, nil
+}
+
+const onLeaveMessage = "The employee is on approved leave that day"
+
+// onLeave reports whether date is a day of the employee's approved leave.
+// Weekends inside a leave request are not leave days.
+func onLeave(q sqlx.Queryer, employeeID, date string) (bool, error) {
+	var leave bool
+	query := `SELECT EXISTS (SELECT 1 FROM leave_requests
+	          WHERE employee_id = $1 AND status = 'approved'
+	            AND $2::date BETWEEN start_date AND end_date)
+	          AND EXTRACT(ISODOW FROM $2::date) < 6`
+	err := sqlx.Get(q, &leave, query, employeeID, date)
+	return leave, err
+}
+
+// leaveWarning describes the assignee's pending or approved leave that an
+// open task's due date falls on, or returns "" when there is none.
+func leaveWarning(q sqlx.Queryer, task models.Task) (string, error) {
+	if task.AssignedTo == nil || task.DueDate == nil || task.Status == "completed" {
+		return "", nil
+	}
+
+	var leave []struct {
+		Type      string `db:"type"`
+		Status    string `db:"status"`
+		StartDate string `db:"start_date"`
+		EndDate   string `db:"end_date"`
+	}
+	query := `SELECT type, status, to_char(start_date, 'YYYY-MM-DD') AS start_date,
+	                 to_char(end_date, 'YYYY-MM-DD') AS end_date
+	          FROM leave_requests
+	          WHERE employee_id = $1 AND status IN ('pending', 'approved')
+	            AND $2::date BETWEEN start_date AND end_date
+	          ORDER BY status
+	          LIMIT 1`
+	if err := sqlx.Select(q, &leave, query, *task.AssignedTo, *task.DueDate); err != nil {
+		return "", err
+	}
+	if len(leave) == 0 {
+		return "", nil
+	}
+
+	l := leave[0]
+	return fmt.Sprintf("The due date falls on the assignee's %s %s leave (%s to %s)",
+		l.Status, l.Type, l.StartDate, l.EndDate), nil
+}
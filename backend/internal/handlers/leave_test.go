@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aalsa/management_dashboard/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+func TestBalanceProblem(t *testing.T) {
+	days := func(v float64) *float64 { return &v }
+	balances := []LeaveBalance{
+		{Year: 2024, Type: "vacation", AllowanceDays: days(25), UsedDays: 10, PendingDays: 5, RemainingDays: days(10)},
+		{Year: 2024, Type: "sick"},
+		{Year: 2024, Type: "other", AllowanceDays: days(2), UsedDays: 2.5, RemainingDays: days(-0.5)},
+	}
+
+	tests := []struct {
+		name      string
+		leaveType string
+		requested float64
+		want      string
+	}{
+		{"within balance", "vacation", 4, ""},
+		{"uses the rest exactly", "vacation", 10, ""},
+		{"over balance", "vacation", 10.5, "Not enough vacation balance for 2024: 10 days left, 10.5 requested"},
+		{"no allowance is unlimited", "sick", 100, ""},
+		{"already overdrawn", "other", 1, "Not enough other balance for 2024: -0.5 days left, 1 requested"},
+		{"unknown type", "sabbatical", 5, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := balanceProblem(balances, tt.leaveType, 2024, tt.requested); got != tt.want {
+				t.Errorf("balanceProblem(%q, %v) = %q, want %q", tt.leaveType, tt.requested, got, tt.want)
+			}
+		})
+	}
+}
+
+var leaveRequestColumns = []string{"id", "employee_id", "type", "start_date", "end_date", "days", "reason",
+	"status", "reviewed_by", "reviewed_at", "review_comment", "created_at", "updated_at"}
+
+func leaveRequestRow(status string, days float64) []driver.Value {
+	now := time.Now()
+	return []driver.Value{"l1", "e1", "vacation", "2026-03-02", "2026-03-06", days, "",
+		status, nil, nil, "", now, now}
+}
+
+func TestApprove(t *testing.T) {
+	approved := fakeStep{match: "UPDATE leave_requests SET status = 'approved'",
+		columns: leaveRequestColumns, rows: [][]driver.Value{leaveRequestRow("approved", 4)}}
+	notPending := approved
+	notPending.rows = nil
+	failing := approved
+	failing.rows, failing.err = nil, errors.New("connection reset")
+
+	tests := []struct {
+		name       string
+		update     fakeStep
+		wantStatus int
+	}{
+		{"pending", approved, http.StatusOK},
+		{"no longer pending", notPending, http.StatusConflict},
+		{"database error", failing, http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			steps := []fakeStep{
+				{match: "SELECT * FROM leave_requests WHERE id", columns: leaveRequestColumns,
+					rows: [][]driver.Value{leaveRequestRow("pending", 5)}},
+				{match: "FOR UPDATE"},
+				// A holiday added since the request was filed leaves four working days.
+				{match: "FROM generate_series($2::date", columns: []string{"year", "days"},
+					rows: [][]driver.Value{{int64(2026), float64(4)}}},
+				{match: "FROM leave_balances", columns: []string{"type", "allowance_days"}},
+				{match: "FROM leave_requests l", columns: []string{"type", "used", "pending"}},
+				tt.update,
+			}
+			if tt.wantStatus == http.StatusOK {
+				steps = append(steps, fakeStep{match: "FROM tasks", columns: []string{"title", "due_date"}})
+			}
+			db, f := newFakeDB(t, steps...)
+
+			h := NewLeaveHandler(db)
+			w := serve(h.Approve, "", gin.Params{{Key: "id", Value: "l1"}},
+				"role", models.RoleAdmin, "userID", "u1", "employeeID", "e2")
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Approve() = %d %s, want %d", w.Code, w.Body, tt.wantStatus)
+			}
+			if days := f.args[5][2]; days != float64(4) {
+				t.Errorf("days = %v, want the recounted 4", days)
+			}
+		})
+	}
+}
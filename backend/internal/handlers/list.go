@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 200
+)
+
+type ListResponse struct {
+	Data       interface{} `json:"data"`
+	Total      int         `json:"total"`
+	Limit      int         `json:"limit"`
+	Offset     int         `json:"offset"`
+	NextOffset *int        `json:"next_offset"`
+}
+
+// listQuery collects the WHERE clause and ORDER BY for a list endpoint from the
+// request's query string. Conditions use ? as the placeholder for their value.
+type listQuery struct {
+	c            *gin.Context
+	conditions   []string
+	args         []interface{}
+	sortFields   map[string]string
+	defaultOrder string
+	err          error
+}
+
+func newListQuery(c *gin.Context, sortFields map[string]string, defaultOrder string) *listQuery {
+	return &listQuery{c: c, sortFields: sortFields, defaultOrder: defaultOrder}
+}
+
+func (q *listQuery) where(condition string, value interface{}) {
+	q.args = append(q.args, value)
+	q.conditions = append(q.conditions, strings.Replace(condition, "?", fmt.Sprintf("$%d", len(q.args)), 1))
+}
+
+func (q *listQuery) text(param, condition string) {
+	if value := q.c.Query(param); value != "" {
+		q.where(condition, value)
+	}
+}
+
+// oneOf accepts a comma-separated list of values, e.g. ?status=todo,in_progress.
+func (q *listQuery) oneOf(param, column string) {
+	if value := q.c.Query(param); value != "" {
+		q.where(column+" = ANY(?)", pq.Array(strings.Split(value, ",")))
+	}
+}
+
+func (q *listQuery) id(param, condition string) {
+	value := q.c.Query(param)
+	if value == "" {
+		return
+	}
+	if _, err := uuid.Parse(value); err != nil {
+		q.fail(fmt.Errorf("%s must be a valid id", param))
+		return
+	}
+	q.where(condition, value)
+}
+
+func (q *listQuery) date(param, condition string) {
+	value := q.c.Query(param)
+	if value == "" {
+		return
+	}
+	if _, err := time.Parse("2006-01-02", value); err != nil {
+		q.fail(fmt.Errorf("%s must be a date in YYYY-MM-DD format", param))
+		return
+	}
+	q.where(condition, value)
+}
+
+func (q *listQuery) boolean(param, condition string) {
+	value := q.c.Query(param)
+	if value == "" {
+		return
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		q.fail(fmt.Errorf("%s must be true or false", param))
+		return
+	}
+	q.where(condition, b)
+}
+
+// parseDate reads a date sent as YYYY-MM-DD, or as the RFC 3339 timestamp the
+// API returns for DATE columns.
+func parseDate(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
+
+func (q *listQuery) fail(err error) {
+	if q.err == nil {
+		q.err = err
+	}
+}
+
+func (q *listQuery) whereClause() string {
+	if len(q.conditions) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(q.conditions, " AND ")
+}
+
+// orderClause turns ?sort=field or ?sort=-field into an ORDER BY on a
+// whitelisted column. id is always appended so pages are stable.
+func (q *listQuery) orderClause() string {
+	order := q.defaultOrder
+	if sort := q.c.Query("sort"); sort != "" {
+		column, ok := q.sortFields[strings.TrimPrefix(sort, "-")]
+		if !ok {
+			q.fail(fmt.Errorf("cannot sort by %s", strings.TrimPrefix(sort, "-")))
+			return ""
+		}
+		order = column
+		if strings.HasPrefix(sort, "-") {
+			order += " DESC"
+		}
+	}
+	return " ORDER BY " + order + ", id"
+}
+
+func parsePage(c *gin.Context) (int, int, error) {
+	limit := defaultPageLimit
+	offset := 0
+
+	if value := c.Query("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > maxPageLimit {
+			return 0, 0, fmt.Errorf("limit must be between 1 and %d", maxPageLimit)
+		}
+		limit = n
+	}
+
+	if value := c.Query("offset"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return 0, 0, fmt.Errorf("offset must be a non-negative integer")
+		}
+		offset = n
+	}
+
+	return limit, offset, nil
+}
+
+// listPage runs the count and page queries against table and writes the
+// ListResponse envelope. dest must be a pointer to a slice. CSV requests get
+// every matching row instead, streamed without paging.
+func listPage(c *gin.Context, db *sqlx.DB, dest interface{}, table string, q *listQuery) {
+	limit, offset, err := parsePage(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	where := q.whereClause()
+	order := q.orderClause()
+	if q.err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": q.err.Error()})
+		return
+	}
+
+	if wantsCSV(c) {
+		query := fmt.Sprintf("SELECT * FROM %s%s%s", table, where, order)
+		streamCSV(c, db, reflect.TypeOf(dest).Elem().Elem(), query, q.args...)
+		return
+	}
+
+	var total int
+	if err := db.Get(&total, "SELECT COUNT(*) FROM "+table+where, q.args...); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	query := fmt.Sprintf("SELECT * FROM %s%s%s LIMIT %d OFFSET %d", table, where, order, limit, offset)
+	if err := db.Select(dest, query, q.args...); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := ListResponse{
+		Data:   reflect.ValueOf(dest).Elem().Interface(),
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	}
+	if offset+limit < total {
+		next := offset + limit
+		response.NextOffset = &next
+	}
+
+	c.JSON(http.StatusOK, response)
+}
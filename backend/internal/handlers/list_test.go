@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// testContext returns a context for a GET request with the query string.
+func testContext(query string) *gin.Context {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/?"+query, nil)
+	return c
+}
+
+func TestParsePage(t *testing.T) {
+	tests := []struct {
+		query      string
+		wantLimit  int
+		wantOffset int
+		wantErr    bool
+	}{
+		{"", defaultPageLimit, 0, false},
+		{"limit=5&offset=10", 5, 10, false},
+		{"limit=1", 1, 0, false},
+		{"limit=200", maxPageLimit, 0, false},
+		{"limit=0", 0, 0, true},
+		{"limit=201", 0, 0, true},
+		{"limit=ten", 0, 0, true},
+		{"offset=-1", 0, 0, true},
+		{"offset=x", 0, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			limit, offset, err := parsePage(testContext(tt.query))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parsePage(%q) error = %v, want error %v", tt.query, err, tt.wantErr)
+			}
+			if limit != tt.wantLimit || offset != tt.wantOffset {
+				t.Errorf("parsePage(%q) = %d, %d, want %d, %d", tt.query, limit, offset, tt.wantLimit, tt.wantOffset)
+			}
+		})
+	}
+}
+
+func TestOrderClause(t *testing.T) {
+	fields := map[string]string{"name": "name", "priority": "CASE priority WHEN 'high' THEN 1 ELSE 2 END"}
+
+	tests := []struct {
+		query   string
+		want    string
+		wantErr bool
+	}{
+		{"", " ORDER BY created_at DESC, id", false},
+		{"sort=name", " ORDER BY name, id", false},
+		{"sort=-name", " ORDER BY name DESC, id", false},
+		{"sort=-priority", " ORDER BY CASE priority WHEN 'high' THEN 1 ELSE 2 END DESC, id", false},
+		{"sort=password", "", true},
+		{"sort=name%3B+DROP+TABLE+users", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q := newListQuery(testContext(tt.query), fields, "created_at DESC")
+			got := q.orderClause()
+			if (q.err != nil) != tt.wantErr {
+				t.Fatalf("orderClause() error = %v, want error %v", q.err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("orderClause() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
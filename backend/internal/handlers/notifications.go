@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/aalsa/management_dashboard/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+type NotificationHandler struct {
+	db *sqlx.DB
+}
+
+func NewNotificationHandler(db *sqlx.DB) *NotificationHandler {
+	return &NotificationHandler{db: db}
+}
+
+var notificationSortFields = map[string]string{
+	"created_at": "created_at",
+}
+
+func (h *NotificationHandler) GetAll(c *gin.Context) {
+	q := newListQuery(c, notificationSortFields, "created_at DESC")
+	q.where("user_id = ?", c.GetString("userID"))
+	if c.Query("unread") == "true" {
+		q.conditions = append(q.conditions, "read_at IS NULL")
+	}
+
+	notifications := []models.Notification{}
+	listPage(c, h.db, &notifications, "notifications", q)
+}
+
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	id := c.Param("id")
+
+	query := `UPDATE notifications SET read_at = COALESCE(read_at, CURRENT_TIMESTAMP) WHERE id = $1 AND user_id = $2`
+	result, err := h.db.Exec(query, id, c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Notification not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Notification marked as read"})
+}
+
+func (h *NotificationHandler) MarkAllRead(c *gin.Context) {
+	query := `UPDATE notifications SET read_at = CURRENT_TIMESTAMP WHERE user_id = $1 AND read_at IS NULL`
+	if _, err := h.db.Exec(query, c.GetString("userID")); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "All notifications marked as read"})
+}
@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/aalsa/management_dashboard/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+type DirectReport struct {
+	models.Employee
+	Depth int `db:"depth" json:"depth"`
+}
+
+type OrgChartNode struct {
+	ID           string          `db:"id" json:"id"`
+	FullName     string          `db:"full_name" json:"full_name"`
+	Email        string          `db:"email" json:"email"`
+	Role         string          `db:"role" json:"role"`
+	DepartmentID string          `db:"department_id" json:"department_id"`
+	Department   string          `db:"department" json:"department"`
+	Status       string          `db:"status" json:"status"`
+	ManagerID    *string         `db:"manager_id" json:"manager_id"`
+	Reports      []*OrgChartNode `db:"-" json:"reports"`
+}
+
+// GetReports lists everyone who reports to the employee, directly (depth 1)
+// or through other managers. ?direct=true limits it to direct reports.
+func (h *EmployeeHandler) GetReports(c *gin.Context) {
+	id := c.Param("id")
+
+	var exists bool
+	if err := h.db.Get(&exists, `SELECT EXISTS (SELECT 1 FROM employees WHERE id = $1)`, id); err != nil || !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Employee not found"})
+		return
+	}
+
+	maxDepth := 0
+	if c.Query("direct") == "true" {
+		maxDepth = 1
+	}
+
+	reports := []DirectReport{}
+	query := `WITH RECURSIVE chain AS (
+	              SELECT id, 1 AS depth FROM employees WHERE manager_id = $1
+	              UNION ALL
+	              SELECT e.id, chain.depth + 1 FROM employees e JOIN chain ON e.manager_id = chain.id
+	              WHERE $2 = 0 OR chain.depth < $2
+	          )
+	          SELECT employees.*, chain.depth FROM chain JOIN ` + employeesTable + ` ON employees.id = chain.id
+	          ORDER BY chain.depth, employees.full_name`
+	if err := h.db.Select(&reports, query, id, maxDepth); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	respondReport(c, reports, csvSection{"reports", reports})
+}
+
+// GetOrgChart returns the reporting tree. The roots are employees without a
+// manager, or the employee in ?root=. ?status= limits it to active or
+// inactive employees; reports of a filtered-out manager move up to the
+// nearest manager that is shown.
+func (h *EmployeeHandler) GetOrgChart(c *gin.Context) {
+	q := newListQuery(c, nil, "")
+	q.oneOf("status", "status")
+	if q.err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": q.err.Error()})
+		return
+	}
+
+	employees := []*OrgChartNode{}
+	query := `SELECT id, full_name, email, role, department_id, department, status, manager_id FROM ` +
+		employeesTable + q.whereClause() + ` ORDER BY full_name`
+	if err := h.db.Select(&employees, query, q.args...); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	managers := map[string]*string{}
+	if err := loadManagers(h.db, managers); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	nodes := map[string]*OrgChartNode{}
+	for _, node := range employees {
+		node.Reports = []*OrgChartNode{}
+		nodes[node.ID] = node
+	}
+
+	roots := []*OrgChartNode{}
+	for _, node := range employees {
+		parent := nearestShown(nodes, managers, node.ManagerID)
+		if parent == nil {
+			roots = append(roots, node)
+			continue
+		}
+		parent.Reports = append(parent.Reports, node)
+	}
+
+	if root := c.Query("root"); root != "" {
+		node, ok := nodes[root]
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Employee not found"})
+			return
+		}
+		roots = []*OrgChartNode{node}
+	}
+
+	c.JSON(http.StatusOK, roots)
+}
+
+func loadManagers(db *sqlx.DB, managers map[string]*string) error {
+	var rows []struct {
+		ID        string  `db:"id"`
+		ManagerID *string `db:"manager_id"`
+	}
+	if err := db.Select(&rows, `SELECT id, manager_id FROM employees`); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		managers[row.ID] = row.ManagerID
+	}
+	return nil
+}
+
+// nearestShown walks up the management chain from managerID to the first
+// manager in nodes. Cycles are rejected on write, so the walk ends.
+func nearestShown(nodes map[string]*OrgChartNode, managers map[string]*string, managerID *string) *OrgChartNode {
+	for managerID != nil {
+		if node, ok := nodes[*managerID]; ok {
+			return node
+		}
+		managerID = managers[*managerID]
+	}
+	return nil
+}
+
+// checkManager rejects a manager that does not exist, is the employee
+// themselves or already reports to the employee, which would close a cycle.
+// employeeID is empty for new employees.
+func checkManager(db sqlx.Queryer, employeeID string, managerID *string) (string, error) {
+	if managerID == nil {
+		return "", nil
+	}
+	if *managerID == employeeID {
+		return "An employee cannot be their own manager", nil
+	}
+
+	var exists bool
+	if err := sqlx.Get(db, &exists, `SELECT EXISTS (SELECT 1 FROM employees WHERE id::text = $1)`, *managerID); err != nil {
+		return "", err
+	}
+	if !exists {
+		return "Manager not found", nil
+	}
+	if employeeID == "" {
+		return "", nil
+	}
+
+	var cycle bool
+	query := `WITH RECURSIVE reports AS (
+	              SELECT id FROM employees WHERE manager_id = $1
+	              UNION
+	              SELECT e.id FROM employees e JOIN reports r ON e.manager_id = r.id
+	          )
+	          SELECT EXISTS (SELECT 1 FROM reports WHERE id = $2)`
+	if err := sqlx.Get(db, &cycle, query, employeeID, *managerID); err != nil {
+		return "", err
+	}
+	if cycle {
+		return "This manager change would create a reporting cycle", nil
+	}
+	return "", nil
+}
+
+// lockAndCheckManager serializes manager changes for the rest of tx, so two
+// concurrent changes cannot form a cycle, then runs checkManager.
+func lockAndCheckManager(tx *sqlx.Tx, employeeID string, managerID *string) (string, error) {
+	if managerID == nil {
+		return "", nil
+	}
+	if _, err := tx.Exec(`LOCK TABLE employees IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+		return "", err
+	}
+	return checkManager(tx, employeeID, managerID)
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/aalsa/management_dashboard/internal/models"
 	"github.com/gin-gonic/gin"
@@ -17,16 +18,36 @@ func NewProjectHandler(db *sqlx.DB) *ProjectHandler {
 	return &ProjectHandler{db: db}
 }
 
+var projectSortFields = map[string]string{
+	"name":       "name",
+	"start_date": "start_date",
+	"end_date":   "end_date",
+	"status":     "status",
+	"budget":     "budget",
+	"created_at": "created_at",
+}
+
 func (h *ProjectHandler) GetAll(c *gin.Context) {
-	var projects []models.Project
-	query := `SELECT * FROM projects ORDER BY start_date DESC`
+	q := newListQuery(c, projectSortFields, "start_date DESC")
+	q.oneOf("status", "status")
+	q.id("client_id", "client_id = ?")
 
-	if err := h.db.Select(&projects, query); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	projects := []models.Project{}
+	listPage(c, h.db, &projects, "projects", q)
+}
+
+func (h *ProjectHandler) GetMine(c *gin.Context) {
+	employeeID, ok := currentEmployee(c)
+	if !ok {
 		return
 	}
 
-	c.JSON(http.StatusOK, projects)
+	q := newListQuery(c, projectSortFields, "start_date DESC")
+	q.oneOf("status", "status")
+	q.where("id IN (SELECT project_id FROM project_assignments WHERE employee_id = ?)", employeeID)
+
+	projects := []models.Project{}
+	listPage(c, h.db, &projects, "projects", q)
 }
 
 func (h *ProjectHandler) GetByID(c *gin.Context) {
@@ -49,16 +70,15 @@ func (h *ProjectHandler) Create(c *gin.Context) {
 		return
 	}
 
-	project.ID = uuid.New().String()
-
-	query := `INSERT INTO projects (id, name, description, start_date, end_date, status, budget)
-	          VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING created_at, updated_at`
-
-	err := h.db.QueryRow(query, project.ID, project.Name, project.Description,
-		project.StartDate, project.EndDate, project.Status, project.Budget).
-		Scan(&project.CreatedAt, &project.UpdatedAt)
+	if problem, err := checkProject(h.db, project); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	} else if problem != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": problem})
+		return
+	}
 
-	if err != nil {
+	if err := insertProject(h.db, &project); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -75,19 +95,20 @@ func (h *ProjectHandler) Update(c *gin.Context) {
 		return
 	}
 
-	query := `UPDATE projects SET name = $1, description = $2, start_date = $3,
-	          end_date = $4, status = $5, budget = $6, updated_at = CURRENT_TIMESTAMP
-	          WHERE id = $7`
-
-	result, err := h.db.Exec(query, project.Name, project.Description, project.StartDate,
-		project.EndDate, project.Status, project.Budget, id)
+	if problem, err := checkProject(h.db, project); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	} else if problem != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": problem})
+		return
+	}
 
+	rows, err := updateProject(h.db, id, project)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	rows, _ := result.RowsAffected()
 	if rows == 0 {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
 		return
@@ -96,6 +117,70 @@ func (h *ProjectHandler) Update(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Project updated"})
 }
 
+var projectStatuses = map[string]bool{"planning": true, "active": true, "completed": true, "on_hold": true}
+
+// checkProject validates the fields shared by Create, Update and the CSV
+// import, and that the client exists.
+func checkProject(db sqlx.Queryer, project models.Project) (string, error) {
+	if strings.TrimSpace(project.Name) == "" {
+		return "name is required", nil
+	}
+	start, err := parseDate(project.StartDate)
+	if err != nil {
+		return "start_date must be a date in YYYY-MM-DD format", nil
+	}
+	if project.EndDate != nil {
+		end, err := parseDate(*project.EndDate)
+		if err != nil {
+			return "end_date must be a date in YYYY-MM-DD format", nil
+		}
+		if end.Before(start) {
+			return "end_date cannot be before start_date", nil
+		}
+	}
+	if !projectStatuses[project.Status] {
+		return "status must be one of planning, active, completed, on_hold", nil
+	}
+	if project.Budget != nil && *project.Budget < 0 {
+		return "budget cannot be negative", nil
+	}
+
+	if project.ClientID != nil {
+		var exists bool
+		if err := sqlx.Get(db, &exists, `SELECT EXISTS (SELECT 1 FROM clients WHERE id::text = $1)`, *project.ClientID); err != nil {
+			return "", err
+		}
+		if !exists {
+			return "Client not found", nil
+		}
+	}
+	return "", nil
+}
+
+func insertProject(db sqlx.Queryer, project *models.Project) error {
+	project.ID = uuid.New().String()
+
+	query := `INSERT INTO projects (id, name, description, start_date, end_date, status, budget, client_id)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING created_at, updated_at`
+
+	return db.QueryRowx(query, project.ID, project.Name, project.Description,
+		project.StartDate, project.EndDate, project.Status, project.Budget, project.ClientID).
+		Scan(&project.CreatedAt, &project.UpdatedAt)
+}
+
+func updateProject(db sqlx.Execer, id string, project models.Project) (int64, error) {
+	query := `UPDATE projects SET name = $1, description = $2, start_date = $3,
+	          end_date = $4, status = $5, budget = $6, client_id = $7, updated_at = CURRENT_TIMESTAMP
+	          WHERE id = $8`
+
+	result, err := db.Exec(query, project.Name, project.Description, project.StartDate,
+		project.EndDate, project.Status, project.Budget, project.ClientID, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 func (h *ProjectHandler) Delete(c *gin.Context) {
 	id := c.Param("id")
 
@@ -114,3 +199,141 @@ func (h *ProjectHandler) Delete(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "Project deleted"})
 }
+
+type AddMemberRequest struct {
+	EmployeeID        string `json:"employee_id" binding:"required"`
+	AllocationPercent int    `json:"allocation_percent" binding:"omitempty,min=1,max=100"`
+	ProjectRole       string `json:"project_role" binding:"max=100"`
+}
+
+func (h *ProjectHandler) GetMembers(c *gin.Context) {
+	id := c.Param("id")
+
+	var exists bool
+	if err := h.db.Get(&exists, `SELECT EXISTS (SELECT 1 FROM projects WHERE id = $1)`, id); err != nil || !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		return
+	}
+
+	members := []models.ProjectMember{}
+	query := `SELECT pa.*, e.full_name, e.email
+	          FROM project_assignments pa
+	          JOIN employees e ON e.id = pa.employee_id
+	          WHERE pa.project_id = $1
+	          ORDER BY e.full_name`
+
+	if err := h.db.Select(&members, query, id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, members)
+}
+
+func (h *ProjectHandler) AddMember(c *gin.Context) {
+	id := c.Param("id")
+	var req AddMemberRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.AllocationPercent == 0 {
+		req.AllocationPercent = 100
+	}
+
+	var exists bool
+	if err := h.db.Get(&exists, `SELECT EXISTS (SELECT 1 FROM projects WHERE id = $1)`, id); err != nil || !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		return
+	}
+
+	if err := h.db.Get(&exists, `SELECT EXISTS (SELECT 1 FROM employees WHERE id = $1)`, req.EmployeeID); err != nil || !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Employee not found"})
+		return
+	}
+
+	if !canAccessEmployee(c, h.db, req.EmployeeID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only staff employees in your department"})
+		return
+	}
+
+	assignment := models.ProjectAssignment{
+		ProjectID:         id,
+		EmployeeID:        req.EmployeeID,
+		AllocationPercent: req.AllocationPercent,
+		ProjectRole:       req.ProjectRole,
+	}
+
+	query := `INSERT INTO project_assignments (project_id, employee_id, allocation_percent, project_role)
+	          VALUES ($1, $2, $3, $4)
+	          ON CONFLICT (project_id, employee_id)
+	          DO UPDATE SET allocation_percent = EXCLUDED.allocation_percent, project_role = EXCLUDED.project_role
+	          RETURNING assigned_at`
+
+	err := h.db.QueryRow(query, assignment.ProjectID, assignment.EmployeeID,
+		assignment.AllocationPercent, assignment.ProjectRole).Scan(&assignment.AssignedAt)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, assignment)
+}
+
+func (h *ProjectHandler) RemoveMember(c *gin.Context) {
+	id := c.Param("id")
+	employeeID := c.Param("employeeId")
+
+	if !canAccessEmployee(c, h.db, employeeID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only staff employees in your department"})
+		return
+	}
+
+	query := `DELETE FROM project_assignments WHERE project_id = $1 AND employee_id = $2`
+	result, err := h.db.Exec(query, id, employeeID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Project member not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Project member removed"})
+}
+
+func (h *ProjectHandler) GetEmployeeProjects(c *gin.Context) {
+	employeeID := c.Param("id")
+
+	var exists bool
+	if err := h.db.Get(&exists, `SELECT EXISTS (SELECT 1 FROM employees WHERE id = $1)`, employeeID); err != nil || !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Employee not found"})
+		return
+	}
+
+	projects := []models.EmployeeProject{}
+	query := `SELECT pa.*, p.name, p.status
+	          FROM project_assignments pa
+	          JOIN projects p ON p.id = pa.project_id
+	          WHERE pa.employee_id = $1
+	          ORDER BY p.name`
+
+	if err := h.db.Select(&projects, query, employeeID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, projects)
+}
+
+func isProjectMember(db sqlx.Queryer, projectID, employeeID string) (bool, error) {
+	var member bool
+	query := `SELECT EXISTS (SELECT 1 FROM project_assignments WHERE project_id = $1 AND employee_id = $2)`
+	err := sqlx.Get(db, &member, query, projectID, employeeID)
+	return member, err
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/aalsa/management_dashboard/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+type RateRequest struct {
+	HourlyRate    float64 `json:"hourly_rate" binding:"gte=0"`
+	EffectiveFrom string  `json:"effective_from" binding:"required"`
+}
+
+// rateAt selects the hourly cost rate in effect for employee_id on log_date
+// from the row aliased as l.
+const rateAt = `
+	LEFT JOIN LATERAL (
+		SELECT er.hourly_rate
+		FROM employee_rates er
+		WHERE er.employee_id = l.employee_id AND er.effective_from <= l.log_date
+		ORDER BY er.effective_from DESC
+		LIMIT 1
+	) r ON true`
+
+func (h *EmployeeHandler) GetRates(c *gin.Context) {
+	id := c.Param("id")
+
+	if !canAccessEmployee(c, h.db, id) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only manage employees in your department"})
+		return
+	}
+
+	rates := []models.EmployeeRate{}
+	query := `SELECT * FROM employee_rates WHERE employee_id = $1 ORDER BY effective_from DESC`
+	if err := h.db.Select(&rates, query, id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, rates)
+}
+
+// AddRate records a rate that applies from effective_from until the next
+// one. Setting a rate for a date that already has one replaces it.
+func (h *EmployeeHandler) AddRate(c *gin.Context) {
+	id := c.Param("id")
+	var req RateRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := time.Parse("2006-01-02", req.EffectiveFrom); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "effective_from must be a date in YYYY-MM-DD format"})
+		return
+	}
+
+	var exists bool
+	if err := h.db.Get(&exists, `SELECT EXISTS (SELECT 1 FROM employees WHERE id = $1)`, id); err != nil || !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Employee not found"})
+		return
+	}
+
+	if !canAccessEmployee(c, h.db, id) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only manage employees in your department"})
+		return
+	}
+
+	var rate models.EmployeeRate
+	query := `INSERT INTO employee_rates (employee_id, hourly_rate, effective_from)
+	          VALUES ($1, $2, $3)
+	          ON CONFLICT (employee_id, effective_from) DO UPDATE SET hourly_rate = EXCLUDED.hourly_rate
+	          RETURNING *`
+	if err := h.db.Get(&rate, query, id, req.HourlyRate, req.EffectiveFrom); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, rate)
+}
+
+func (h *EmployeeHandler) DeleteRate(c *gin.Context) {
+	id := c.Param("id")
+
+	if !canAccessEmployee(c, h.db, id) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only manage employees in your department"})
+		return
+	}
+
+	query := `DELETE FROM employee_rates WHERE id = $1 AND employee_id = $2`
+	result, err := h.db.Exec(query, c.Param("rateId"), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Rate not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Rate deleted"})
+}
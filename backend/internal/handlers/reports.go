@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+type ReportHandler struct {
+	db *sqlx.DB
+}
+
+func NewReportHandler(db *sqlx.DB) *ReportHandler {
+	return &ReportHandler{db: db}
+}
+
+type TaskOverrun struct {
+	TaskID         string  `db:"task_id" json:"task_id"`
+	Title          string  `db:"title" json:"title"`
+	ProjectID      string  `db:"project_id" json:"project_id"`
+	AssignedTo     *string `db:"assigned_to" json:"assigned_to"`
+	EstimatedHours float64 `db:"estimated_hours" json:"estimated_hours"`
+	ActualHours    float64 `db:"actual_hours" json:"actual_hours"`
+	OverrunPercent float64 `db:"overrun_percent" json:"overrun_percent"`
+}
+
+type GroupOverrun struct {
+	ID             string  `db:"id" json:"id"`
+	Name           string  `db:"name" json:"name"`
+	EstimatedHours float64 `db:"estimated_hours" json:"estimated_hours"`
+	ActualHours    float64 `db:"actual_hours" json:"actual_hours"`
+	OverrunPercent float64 `db:"overrun_percent" json:"overrun_percent"`
+}
+
+type EstimationAccuracy struct {
+	EmployeeID          string  `db:"employee_id" json:"employee_id"`
+	FullName            string  `db:"full_name" json:"full_name"`
+	CompletedTasks      int     `db:"completed_tasks" json:"completed_tasks"`
+	EstimatedHours      float64 `db:"estimated_hours" json:"estimated_hours"`
+	ActualHours         float64 `db:"actual_hours" json:"actual_hours"`
+	ActualToEstimate    float64 `db:"actual_to_estimate" json:"actual_to_estimate"`
+	MeanAbsErrorPercent float64 `db:"mean_abs_error_percent" json:"mean_abs_error_percent"`
+}
+
+type EstimatesReport struct {
+	ThresholdPercent float64              `json:"threshold_percent"`
+	Tasks            []TaskOverrun        `json:"tasks"`
+	Employees        []GroupOverrun       `json:"employees"`
+	Projects         []GroupOverrun       `json:"projects"`
+	Accuracy         []EstimationAccuracy `json:"accuracy"`
+}
+
+// estimatedTasks pairs every estimated task with the hours logged against it.
+const estimatedTasks = `WITH estimated AS (
+	SELECT t.id, t.title, t.project_id, t.assigned_to, t.status, t.estimated_hours,
+	       COALESCE((SELECT SUM(l.hours) FROM time_logs l WHERE l.task_id = t.id), 0) AS actual_hours
+	FROM tasks t
+	WHERE t.estimated_hours IS NOT NULL
+) `
+
+// GetEstimates lists tasks, employees and projects whose logged hours exceed
+// their estimates by more than ?threshold percent (default 20), plus each
+// assignee's accuracy on completed, estimated tasks.
+func (h *ReportHandler) GetEstimates(c *gin.Context) {
+	threshold := 20.0
+	if value := c.Query("threshold"); value != "" {
+		t, err := strconv.ParseFloat(value, 64)
+		if err != nil || t < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "threshold must be a non-negative percentage"})
+			return
+		}
+		threshold = t
+	}
+	factor := 1 + threshold/100
+
+	report := EstimatesReport{
+		ThresholdPercent: threshold,
+		Tasks:            []TaskOverrun{},
+		Employees:        []GroupOverrun{},
+		Projects:         []GroupOverrun{},
+		Accuracy:         []EstimationAccuracy{},
+	}
+
+	tasksQuery := estimatedTasks + `
+	SELECT id AS task_id, title, project_id, assigned_to, estimated_hours, actual_hours,
+	       ROUND((actual_hours / estimated_hours - 1) * 100, 1) AS overrun_percent
+	FROM estimated
+	WHERE actual_hours > estimated_hours * $1
+	ORDER BY overrun_percent DESC`
+	if err := h.db.Select(&report.Tasks, tasksQuery, factor); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	employeesQuery := estimatedTasks + `
+	SELECT e.id, e.full_name AS name, SUM(x.estimated_hours) AS estimated_hours, SUM(x.actual_hours) AS actual_hours,
+	       ROUND((SUM(x.actual_hours) / SUM(x.estimated_hours) - 1) * 100, 1) AS overrun_percent
+	FROM estimated x
+	JOIN employees e ON e.id = x.assigned_to
+	GROUP BY e.id, e.full_name
+	HAVING SUM(x.actual_hours) > SUM(x.estimated_hours) * $1
+	ORDER BY overrun_percent DESC`
+	if err := h.db.Select(&report.Employees, employeesQuery, factor); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	projectsQuery := estimatedTasks + `
+	SELECT p.id, p.name, SUM(x.estimated_hours) AS estimated_hours, SUM(x.actual_hours) AS actual_hours,
+	       ROUND((SUM(x.actual_hours) / SUM(x.estimated_hours) - 1) * 100, 1) AS overrun_percent
+	FROM estimated x
+	JOIN projects p ON p.id = x.project_id
+	GROUP BY p.id, p.name
+	HAVING SUM(x.actual_hours) > SUM(x.estimated_hours) * $1
+	ORDER BY overrun_percent DESC`
+	if err := h.db.Select(&report.Projects, projectsQuery, factor); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	accuracyQuery := estimatedTasks + `
+	SELECT e.id AS employee_id, e.full_name, COUNT(*) AS completed_tasks,
+	       SUM(x.estimated_hours) AS estimated_hours, SUM(x.actual_hours) AS actual_hours,
+	       ROUND(SUM(x.actual_hours) / SUM(x.estimated_hours), 2) AS actual_to_estimate,
+	       ROUND(AVG(ABS(x.actual_hours - x.estimated_hours) / x.estimated_hours) * 100, 1) AS mean_abs_error_percent
+	FROM estimated x
+	JOIN employees e ON e.id = x.assigned_to
+	WHERE x.status = 'completed'
+	GROUP BY e.id, e.full_name
+	ORDER BY mean_abs_error_percent`
+	if err := h.db.Select(&report.Accuracy, accuracyQuery); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	respondReport(c, report, csvSection{"tasks", report.Tasks}, csvSection{"employees", report.Employees},
+		csvSection{"projects", report.Projects}, csvSection{"accuracy", report.Accuracy})
+}
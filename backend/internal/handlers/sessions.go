@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"github.com/aalsa/management_dashboard/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	accessTokenTTL  = 15 * time.Minute
+	refreshTokenTTL = 30 * 24 * time.Hour
+)
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type SessionResponse struct {
+	models.Session
+	Current bool `json:"current"`
+}
+
+func (h *AuthHandler) startSession(c *gin.Context, user models.User) (AuthResponse, error) {
+	refreshToken, err := newRefreshToken()
+	if err != nil {
+		return AuthResponse{}, err
+	}
+
+	sessionID := uuid.New().String()
+	query := `INSERT INTO sessions (id, user_id, refresh_token_hash, user_agent, ip_address, expires_at)
+	          VALUES ($1, $2, $3, $4, $5, $6)`
+	_, err = h.db.Exec(query, sessionID, user.ID, hashToken(refreshToken),
+		c.Request.UserAgent(), c.ClientIP(), time.Now().Add(refreshTokenTTL))
+	if err != nil {
+		return AuthResponse{}, err
+	}
+
+	token, err := generateToken(user, sessionID)
+	if err != nil {
+		return AuthResponse{}, err
+	}
+
+	return AuthResponse{Token: token, RefreshToken: refreshToken, User: &user}, nil
+}
+
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	hash := hashToken(req.RefreshToken)
+
+	// A rotated-out token being presented again means it leaked, so the whole
+	// session is killed rather than letting either holder keep using it.
+	reuseQuery := `UPDATE sessions SET revoked_at = CURRENT_TIMESTAMP
+	               WHERE previous_token_hash = $1 AND revoked_at IS NULL`
+	if result, err := h.db.Exec(reuseQuery, hash); err == nil {
+		if rows, _ := result.RowsAffected(); rows > 0 {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token reuse detected, session revoked"})
+			return
+		}
+	}
+
+	var session models.Session
+	query := `SELECT * FROM sessions
+	          WHERE refresh_token_hash = $1 AND revoked_at IS NULL AND expires_at > CURRENT_TIMESTAMP`
+	if err := h.db.Get(&session, query, hash); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
+		return
+	}
+
+	var user models.User
+	if err := h.db.Get(&user, `SELECT * FROM users WHERE id = $1`, session.UserID); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
+		return
+	}
+
+	refreshToken, err := newRefreshToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	rotateQuery := `UPDATE sessions SET refresh_token_hash = $1, previous_token_hash = $2,
+	                last_used_at = CURRENT_TIMESTAMP, expires_at = $3
+	                WHERE id = $4 AND refresh_token_hash = $2`
+	result, err := h.db.Exec(rotateQuery, hashToken(refreshToken), hash, time.Now().Add(refreshTokenTTL), session.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
+		return
+	}
+
+	token, err := generateToken(user, session.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, AuthResponse{Token: token, RefreshToken: refreshToken, User: &user})
+}
+
+func (h *AuthHandler) Logout(c *gin.Context) {
+	query := `UPDATE sessions SET revoked_at = CURRENT_TIMESTAMP WHERE id = $1 AND revoked_at IS NULL`
+	if _, err := h.db.Exec(query, c.GetString("sessionID")); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out"})
+}
+
+// revokeSessions signs the user out everywhere, so tokens carrying their old
+// role or employee link stop working.
+func revokeSessions(tx *sqlx.Tx, userID string) error {
+	_, err := tx.Exec(`UPDATE sessions SET revoked_at = CURRENT_TIMESTAMP WHERE user_id = $1 AND revoked_at IS NULL`, userID)
+	return err
+}
+
+func (h *AuthHandler) GetSessions(c *gin.Context) {
+	var sessions []models.Session
+	query := `SELECT * FROM sessions
+	          WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > CURRENT_TIMESTAMP
+	          ORDER BY last_used_at DESC`
+
+	if err := h.db.Select(&sessions, query, c.GetString("userID")); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := make([]SessionResponse, len(sessions))
+	for i, session := range sessions {
+		response[i] = SessionResponse{Session: session, Current: session.ID == c.GetString("sessionID")}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	id := c.Param("id")
+
+	query := `UPDATE sessions SET revoked_at = CURRENT_TIMESTAMP
+	          WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`
+	result, err := h.db.Exec(query, id, c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Session revoked"})
+}
+
+func newRefreshToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
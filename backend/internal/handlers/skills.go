@@ -0,0 +1,474 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/aalsa/management_dashboard/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+type SkillHandler struct {
+	db *sqlx.DB
+}
+
+func NewSkillHandler(db *sqlx.DB) *SkillHandler {
+	return &SkillHandler{db: db}
+}
+
+type SkillRequest struct {
+	Name     string `json:"name" binding:"required,max=100"`
+	Category string `json:"category" binding:"max=100"`
+}
+
+// Proficiency runs from 1 (novice) to 5 (expert).
+type ProficiencyRequest struct {
+	Proficiency int `json:"proficiency" binding:"required,min=1,max=5"`
+}
+
+type RequiredSkillRequest struct {
+	MinProficiency int `json:"min_proficiency" binding:"omitempty,min=1,max=5"`
+}
+
+var skillSortFields = map[string]string{
+	"name":       "name",
+	"category":   "category",
+	"created_at": "created_at",
+}
+
+func (h *SkillHandler) GetAll(c *gin.Context) {
+	q := newListQuery(c, skillSortFields, "name")
+	q.text("name", "name ILIKE '%' || ? || '%'")
+	q.text("category", "lower(category) = lower(?)")
+
+	skills := []models.Skill{}
+	listPage(c, h.db, &skills, "skills", q)
+}
+
+func (h *SkillHandler) GetByID(c *gin.Context) {
+	var skill models.Skill
+	if err := h.db.Get(&skill, `SELECT * FROM skills WHERE id = $1`, c.Param("id")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Skill not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, skill)
+}
+
+func (h *SkillHandler) Create(c *gin.Context) {
+	var req SkillRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if status, problem, err := h.check("", req); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	} else if problem != "" {
+		c.JSON(status, gin.H{"error": problem})
+		return
+	}
+
+	var skill models.Skill
+	query := `INSERT INTO skills (name, category) VALUES ($1, $2) RETURNING *`
+	if err := h.db.Get(&skill, query, strings.TrimSpace(req.Name), strings.TrimSpace(req.Category)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, skill)
+}
+
+func (h *SkillHandler) Update(c *gin.Context) {
+	id := c.Param("id")
+	var req SkillRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if status, problem, err := h.check(id, req); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	} else if problem != "" {
+		c.JSON(status, gin.H{"error": problem})
+		return
+	}
+
+	query := `UPDATE skills SET name = $1, category = $2, updated_at = CURRENT_TIMESTAMP WHERE id = $3`
+	result, err := h.db.Exec(query, strings.TrimSpace(req.Name), strings.TrimSpace(req.Category), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Skill not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Skill updated"})
+}
+
+// Delete removes the skill from every employee and task as well.
+func (h *SkillHandler) Delete(c *gin.Context) {
+	result, err := h.db.Exec(`DELETE FROM skills WHERE id = $1`, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Skill not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Skill deleted"})
+}
+
+// check rejects blank or duplicate names, ignoring case. id is empty for new
+// skills.
+func (h *SkillHandler) check(id string, req SkillRequest) (int, string, error) {
+	if strings.TrimSpace(req.Name) == "" {
+		return http.StatusBadRequest, "name is required", nil
+	}
+
+	var taken bool
+	query := `SELECT EXISTS (SELECT 1 FROM skills WHERE lower(name) = lower($1) AND id::text <> $2)`
+	if err := h.db.Get(&taken, query, strings.TrimSpace(req.Name), id); err != nil {
+		return 0, "", err
+	}
+	if taken {
+		return http.StatusConflict, "A skill with this name already exists", nil
+	}
+	return 0, "", nil
+}
+
+func (h *EmployeeHandler) GetSkills(c *gin.Context) {
+	id := c.Param("id")
+
+	var exists bool
+	if err := h.db.Get(&exists, `SELECT EXISTS (SELECT 1 FROM employees WHERE id = $1)`, id); err != nil || !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Employee not found"})
+		return
+	}
+
+	skills := []models.EmployeeSkill{}
+	query := `SELECT es.*, s.name, s.category
+	          FROM employee_skills es
+	          JOIN skills s ON s.id = es.skill_id
+	          WHERE es.employee_id = $1
+	          ORDER BY es.proficiency DESC, s.name`
+	if err := h.db.Select(&skills, query, id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, skills)
+}
+
+// SetSkill adds the skill to the employee or changes its proficiency.
+func (h *EmployeeHandler) SetSkill(c *gin.Context) {
+	id := c.Param("id")
+	skillID := c.Param("skillId")
+
+	var req ProficiencyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var exists bool
+	if err := h.db.Get(&exists, `SELECT EXISTS (SELECT 1 FROM employees WHERE id = $1)`, id); err != nil || !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Employee not found"})
+		return
+	}
+
+	if !canAccessEmployee(c, h.db, id) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only manage employees in your department"})
+		return
+	}
+
+	if err := h.db.Get(&exists, `SELECT EXISTS (SELECT 1 FROM skills WHERE id = $1)`, skillID); err != nil || !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Skill not found"})
+		return
+	}
+
+	query := `INSERT INTO employee_skills (employee_id, skill_id, proficiency) VALUES ($1, $2, $3)
+	          ON CONFLICT (employee_id, skill_id)
+	          DO UPDATE SET proficiency = EXCLUDED.proficiency, updated_at = CURRENT_TIMESTAMP`
+	if _, err := h.db.Exec(query, id, skillID, req.Proficiency); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Employee skill saved"})
+}
+
+func (h *EmployeeHandler) RemoveSkill(c *gin.Context) {
+	id := c.Param("id")
+
+	if !canAccessEmployee(c, h.db, id) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only manage employees in your department"})
+		return
+	}
+
+	query := `DELETE FROM employee_skills WHERE employee_id = $1 AND skill_id = $2`
+	result, err := h.db.Exec(query, id, c.Param("skillId"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Employee skill not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Employee skill removed"})
+}
+
+func (h *TaskHandler) GetSkills(c *gin.Context) {
+	id := c.Param("id")
+
+	var exists bool
+	if err := h.db.Get(&exists, `SELECT EXISTS (SELECT 1 FROM tasks WHERE id = $1)`, id); err != nil || !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+
+	skills, err := requiredSkills(h.db, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, skills)
+}
+
+// SetSkill requires the skill on the task at min_proficiency (default 1), or
+// changes the level of an already required skill.
+func (h *TaskHandler) SetSkill(c *gin.Context) {
+	id := c.Param("id")
+	skillID := c.Param("skillId")
+
+	var req RequiredSkillRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.MinProficiency == 0 {
+		req.MinProficiency = 1
+	}
+
+	var task models.Task
+	if err := h.db.Get(&task, `SELECT * FROM tasks WHERE id = $1`, id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+
+	if !canAccessTask(c, h.db, task.AssignedTo) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You cannot manage tasks for this assignee"})
+		return
+	}
+
+	var exists bool
+	if err := h.db.Get(&exists, `SELECT EXISTS (SELECT 1 FROM skills WHERE id = $1)`, skillID); err != nil || !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Skill not found"})
+		return
+	}
+
+	query := `INSERT INTO task_skills (task_id, skill_id, min_proficiency) VALUES ($1, $2, $3)
+	          ON CONFLICT (task_id, skill_id) DO UPDATE SET min_proficiency = EXCLUDED.min_proficiency`
+	if _, err := h.db.Exec(query, id, skillID, req.MinProficiency); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Required skill saved"})
+}
+
+func (h *TaskHandler) RemoveSkill(c *gin.Context) {
+	id := c.Param("id")
+
+	var task models.Task
+	if err := h.db.Get(&task, `SELECT * FROM tasks WHERE id = $1`, id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+
+	if !canAccessTask(c, h.db, task.AssignedTo) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You cannot manage tasks for this assignee"})
+		return
+	}
+
+	result, err := h.db.Exec(`DELETE FROM task_skills WHERE task_id = $1 AND skill_id = $2`, id, c.Param("skillId"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Required skill not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Required skill removed"})
+}
+
+func requiredSkills(db *sqlx.DB, taskID string) ([]models.TaskSkill, error) {
+	skills := []models.TaskSkill{}
+	query := `SELECT ts.*, s.name, s.category
+	          FROM task_skills ts
+	          JOIN skills s ON s.id = ts.skill_id
+	          WHERE ts.task_id = $1
+	          ORDER BY s.name`
+	err := db.Select(&skills, query, taskID)
+	return skills, err
+}
+
+type SkillSupply struct {
+	SkillID        string   `db:"skill_id" json:"skill_id"`
+	Name           string   `db:"name" json:"name"`
+	Category       string   `db:"category" json:"category"`
+	Employees      int      `db:"employees" json:"employees"`
+	Proficient     int      `db:"proficient" json:"proficient"`
+	AvgProficiency *float64 `db:"avg_proficiency" json:"avg_proficiency"`
+	OpenTasks      int      `db:"open_tasks" json:"open_tasks"`
+	Gap            bool     `db:"gap" json:"gap"`
+}
+
+type SkillsMatrixCell struct {
+	EmployeeID  string `db:"employee_id" json:"employee_id"`
+	FullName    string `db:"full_name" json:"full_name"`
+	SkillID     string `db:"skill_id" json:"skill_id"`
+	Name        string `db:"name" json:"name"`
+	Proficiency int    `db:"proficiency" json:"proficiency"`
+}
+
+type SkillsMatrixRow struct {
+	EmployeeID string         `json:"employee_id"`
+	FullName   string         `json:"full_name"`
+	Skills     map[string]int `json:"skills"`
+}
+
+type SkillsMatrix struct {
+	DepartmentID string            `json:"department_id"`
+	Level        int               `json:"level"`
+	Skills       []SkillSupply     `json:"skills"`
+	Employees    []SkillsMatrixRow `json:"employees"`
+}
+
+// GetSkillsMatrix shows the proficiency of each active employee in the
+// department for every skill the department has or needs. Per skill it
+// counts the employees who have it and those at ?level or above (default 3),
+// and the open tasks requiring it in projects the department is staffed on.
+// A skill is a gap when open tasks need it but nobody in the department is
+// proficient. Each employee's skills map skill ids to proficiency.
+func (h *DepartmentHandler) GetSkillsMatrix(c *gin.Context) {
+	id := c.Param("id")
+
+	level := 3
+	if value := c.Query("level"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > 5 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "level must be between 1 and 5"})
+			return
+		}
+		level = n
+	}
+
+	var exists bool
+	if err := h.db.Get(&exists, `SELECT EXISTS (SELECT 1 FROM departments WHERE id = $1)`, id); err != nil || !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Department not found"})
+		return
+	}
+
+	skills := []SkillSupply{}
+	query := `
+	WITH staff AS (
+		SELECT id FROM employees WHERE department_id = $1 AND status = 'active'
+	),
+	supply AS (
+		SELECT es.skill_id, COUNT(*) AS employees,
+		       COUNT(*) FILTER (WHERE es.proficiency >= $2) AS proficient,
+		       ROUND(AVG(es.proficiency), 1) AS avg_proficiency
+		FROM employee_skills es
+		JOIN staff ON staff.id = es.employee_id
+		GROUP BY 1
+	),
+	demand AS (
+		SELECT ts.skill_id, COUNT(DISTINCT t.id) AS open_tasks
+		FROM task_skills ts
+		JOIN tasks t ON t.id = ts.task_id
+		WHERE t.completed_at IS NULL
+		  AND t.project_id IN (SELECT pa.project_id FROM project_assignments pa JOIN staff ON staff.id = pa.employee_id)
+		GROUP BY 1
+	)
+	SELECT s.id AS skill_id, s.name, s.category,
+	       COALESCE(sp.employees, 0) AS employees,
+	       COALESCE(sp.proficient, 0) AS proficient,
+	       sp.avg_proficiency,
+	       COALESCE(d.open_tasks, 0) AS open_tasks,
+	       COALESCE(d.open_tasks, 0) > 0 AND COALESCE(sp.proficient, 0) = 0 AS gap
+	FROM skills s
+	LEFT JOIN supply sp ON sp.skill_id = s.id
+	LEFT JOIN demand d ON d.skill_id = s.id
+	WHERE sp.skill_id IS NOT NULL OR d.skill_id IS NOT NULL
+	ORDER BY s.category, s.name`
+	if err := h.db.Select(&skills, query, id, level); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	cells := []SkillsMatrixCell{}
+	query = `SELECT e.id AS employee_id, e.full_name, es.skill_id, s.name, es.proficiency
+	         FROM employees e
+	         LEFT JOIN employee_skills es ON es.employee_id = e.id
+	         LEFT JOIN skills s ON s.id = es.skill_id
+	         WHERE e.department_id = $1 AND e.status = 'active'
+	         ORDER BY e.full_name, e.id, s.name`
+	var rows []struct {
+		EmployeeID  string  `db:"employee_id"`
+		FullName    string  `db:"full_name"`
+		SkillID     *string `db:"skill_id"`
+		Name        *string `db:"name"`
+		Proficiency *int    `db:"proficiency"`
+	}
+	if err := h.db.Select(&rows, query, id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	employees := []SkillsMatrixRow{}
+	for _, row := range rows {
+		if len(employees) == 0 || employees[len(employees)-1].EmployeeID != row.EmployeeID {
+			employees = append(employees, SkillsMatrixRow{
+				EmployeeID: row.EmployeeID,
+				FullName:   row.FullName,
+				Skills:     map[string]int{},
+			})
+		}
+		if row.SkillID == nil {
+			continue
+		}
+		employees[len(employees)-1].Skills[*row.SkillID] = *row.Proficiency
+		cells = append(cells, SkillsMatrixCell{
+			EmployeeID:  row.EmployeeID,
+			FullName:    row.FullName,
+			SkillID:     *row.SkillID,
+			Name:        *row.Name,
+			Proficiency: *row.Proficiency,
+		})
+	}
+
+	matrix := SkillsMatrix{DepartmentID: id, Level: level, Skills: skills, Employees: employees}
+	respondReport(c, matrix, csvSection{"skills", skills}, csvSection{"cells", cells})
+}
@@ -0,0 +1,484 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/aalsa/management_dashboard/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type SprintHandler struct {
+	db *sqlx.DB
+}
+
+func NewSprintHandler(db *sqlx.DB) *SprintHandler {
+	return &SprintHandler{db: db}
+}
+
+type SprintRequest struct {
+	Name      string `json:"name" binding:"required,max=255"`
+	Goal      string `json:"goal"`
+	StartDate string `json:"start_date" binding:"required"`
+	EndDate   string `json:"end_date" binding:"required"`
+}
+
+type SprintTaskRequest struct {
+	TaskID string `json:"task_id" binding:"required"`
+}
+
+type CloseSprintRequest struct {
+	CarryOverTo *string `json:"carry_over_to"`
+}
+
+type ScopeChange struct {
+	Committed int `db:"committed" json:"committed"`
+	Added     int `db:"added" json:"added"`
+	Removed   int `db:"removed" json:"removed"`
+}
+
+type SprintDetail struct {
+	models.Sprint
+	Tasks []models.SprintTask `json:"tasks"`
+	Scope ScopeChange         `json:"scope"`
+}
+
+type BurndownDay struct {
+	Date      time.Time `db:"day" json:"date"`
+	Scope     float64   `db:"scope" json:"scope"`
+	Remaining float64   `db:"remaining" json:"remaining"`
+	Ideal     float64   `db:"-" json:"ideal"`
+}
+
+type BurndownResponse struct {
+	SprintID string        `json:"sprint_id"`
+	Metric   string        `json:"metric"`
+	Days     []BurndownDay `json:"days"`
+}
+
+func (h *SprintHandler) GetByProject(c *gin.Context) {
+	projectID := c.Param("id")
+
+	var exists bool
+	if err := h.db.Get(&exists, `SELECT EXISTS (SELECT 1 FROM projects WHERE id = $1)`, projectID); err != nil || !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		return
+	}
+
+	sprints := []models.Sprint{}
+	query := `SELECT * FROM sprints WHERE project_id = $1 ORDER BY start_date DESC`
+	if err := h.db.Select(&sprints, query, projectID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, sprints)
+}
+
+func (h *SprintHandler) GetByID(c *gin.Context) {
+	sprint, ok := h.load(c)
+	if !ok {
+		return
+	}
+
+	detail := SprintDetail{Sprint: sprint, Tasks: []models.SprintTask{}}
+	query := `SELECT t.*, st.committed, st.added_at, st.carried_over
+	          FROM sprint_tasks st
+	          JOIN tasks t ON t.id = st.task_id
+	          WHERE st.sprint_id = $1 AND st.removed_at IS NULL
+	          ORDER BY t.due_date, t.title`
+	if err := h.db.Select(&detail.Tasks, query, sprint.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	scopeQuery := `SELECT COUNT(*) FILTER (WHERE committed) AS committed,
+	                      COUNT(*) FILTER (WHERE NOT committed) AS added,
+	                      COUNT(*) FILTER (WHERE removed_at IS NOT NULL) AS removed
+	               FROM sprint_tasks WHERE sprint_id = $1`
+	if err := h.db.Get(&detail.Scope, scopeQuery, sprint.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, detail)
+}
+
+func (h *SprintHandler) Create(c *gin.Context) {
+	projectID := c.Param("id")
+	var req SprintRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if problem := validateSprintDates(req); problem != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": problem})
+		return
+	}
+
+	var exists bool
+	if err := h.db.Get(&exists, `SELECT EXISTS (SELECT 1 FROM projects WHERE id = $1)`, projectID); err != nil || !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		return
+	}
+
+	var sprint models.Sprint
+	query := `INSERT INTO sprints (id, project_id, name, goal, start_date, end_date)
+	          VALUES ($1, $2, $3, $4, $5, $6) RETURNING *`
+	if err := h.db.Get(&sprint, query, uuid.New().String(), projectID,
+		req.Name, req.Goal, req.StartDate, req.EndDate); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, sprint)
+}
+
+func (h *SprintHandler) Update(c *gin.Context) {
+	id := c.Param("id")
+	var req SprintRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if problem := validateSprintDates(req); problem != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": problem})
+		return
+	}
+
+	query := `UPDATE sprints SET name = $1, goal = $2, start_date = $3, end_date = $4, updated_at = CURRENT_TIMESTAMP
+	          WHERE id = $5 AND status <> 'closed'`
+	result, err := h.db.Exec(query, req.Name, req.Goal, req.StartDate, req.EndDate, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Open sprint not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Sprint updated"})
+}
+
+func (h *SprintHandler) Delete(c *gin.Context) {
+	id := c.Param("id")
+
+	query := `DELETE FROM sprints WHERE id = $1`
+	result, err := h.db.Exec(query, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Sprint not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Sprint deleted"})
+}
+
+// AddTask puts a task in the sprint. Tasks added before the sprint starts are
+// its commitment; anything added later counts as scope change.
+func (h *SprintHandler) AddTask(c *gin.Context) {
+	sprint, ok := h.load(c)
+	if !ok {
+		return
+	}
+
+	var req SprintTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if sprint.Status == "closed" {
+		c.JSON(http.StatusConflict, gin.H{"error": "Sprint is closed"})
+		return
+	}
+
+	var projectID string
+	if err := h.db.Get(&projectID, `SELECT project_id FROM tasks WHERE id = $1`, req.TaskID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+	if projectID != sprint.ProjectID {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Task belongs to a different project"})
+		return
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer tx.Rollback()
+
+	if err := addSprintTask(tx, sprint, req.TaskID); err != nil {
+		if errors.Is(err, errTaskInOpenSprint) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Task is already in an open sprint"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Task added to sprint"})
+}
+
+func (h *SprintHandler) RemoveTask(c *gin.Context) {
+	sprint, ok := h.load(c)
+	if !ok {
+		return
+	}
+
+	if sprint.Status == "closed" {
+		c.JSON(http.StatusConflict, gin.H{"error": "Sprint is closed"})
+		return
+	}
+
+	// Removing a task before the sprint starts is just replanning, so no trace
+	// is kept. Once it is active the removal is recorded as scope change.
+	query := `UPDATE sprint_tasks SET removed_at = CURRENT_TIMESTAMP
+	          WHERE sprint_id = $1 AND task_id = $2 AND removed_at IS NULL`
+	if sprint.Status == "planned" {
+		query = `DELETE FROM sprint_tasks WHERE sprint_id = $1 AND task_id = $2 AND removed_at IS NULL`
+	}
+
+	result, err := h.db.Exec(query, sprint.ID, c.Param("taskId"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task is not in this sprint"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Task removed from sprint"})
+}
+
+func (h *SprintHandler) Start(c *gin.Context) {
+	id := c.Param("id")
+
+	query := `UPDATE sprints SET status = 'active', started_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+	          WHERE id = $1 AND status = 'planned'`
+	result, err := h.db.Exec(query, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Only planned sprints can be started"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Sprint started"})
+}
+
+// Close ends the sprint. Unfinished tasks are flagged as carried over and,
+// when carry_over_to is given, moved into that sprint.
+func (h *SprintHandler) Close(c *gin.Context) {
+	sprint, ok := h.load(c)
+	if !ok {
+		return
+	}
+
+	var req CloseSprintRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	if sprint.Status != "active" {
+		c.JSON(http.StatusConflict, gin.H{"error": "Only active sprints can be closed"})
+		return
+	}
+
+	var target models.Sprint
+	if req.CarryOverTo != nil {
+		if err := h.db.Get(&target, `SELECT * FROM sprints WHERE id = $1`, *req.CarryOverTo); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Carry-over sprint not found"})
+			return
+		}
+		if target.ProjectID != sprint.ProjectID || target.Status == "closed" || target.ID == sprint.ID {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Carry-over sprint must be another open sprint in the same project"})
+			return
+		}
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer tx.Rollback()
+
+	var unfinished []string
+	carryQuery := `UPDATE sprint_tasks st SET carried_over = TRUE
+	               FROM tasks t
+	               WHERE t.id = st.task_id AND st.sprint_id = $1 AND st.removed_at IS NULL AND t.status <> 'completed'
+	               RETURNING st.task_id`
+	if err := tx.Select(&unfinished, carryQuery, sprint.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	closeQuery := `UPDATE sprints SET status = 'closed', closed_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+	               WHERE id = $1`
+	if _, err := tx.Exec(closeQuery, sprint.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.CarryOverTo != nil {
+		for _, taskID := range unfinished {
+			if err := addSprintTask(tx, target, taskID); err != nil && !errors.Is(err, errTaskInOpenSprint) {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Sprint closed", "carried_over": len(unfinished), "carry_over_to": req.CarryOverTo})
+}
+
+// burndownMetrics maps ?metric= to what each task in scope contributes.
+var burndownMetrics = map[string]string{
+	"tasks":  "1",
+	"points": "COALESCE(t.story_points, 0)",
+	"hours":  "COALESCE(t.estimated_hours, 0)",
+}
+
+// GetBurndown returns, for each day of the sprint up to today, the work in
+// scope at the end of that day and how much of it was still open. Work is
+// counted in tasks, story points or estimated hours.
+func (h *SprintHandler) GetBurndown(c *gin.Context) {
+	sprint, ok := h.load(c)
+	if !ok {
+		return
+	}
+
+	metric := c.DefaultQuery("metric", "tasks")
+	value, ok := burndownMetrics[metric]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "metric must be one of tasks, points, hours"})
+		return
+	}
+
+	response := BurndownResponse{SprintID: sprint.ID, Metric: metric, Days: []BurndownDay{}}
+	query := `SELECT d::date AS day,
+	                 COALESCE(SUM(` + value + `) FILTER (WHERE st.id IS NOT NULL), 0) AS scope,
+	                 COALESCE(SUM(` + value + `) FILTER (WHERE st.id IS NOT NULL
+	                     AND (t.completed_at IS NULL OR t.completed_at >= d + INTERVAL '1 day')), 0) AS remaining
+	          FROM sprints s
+	          CROSS JOIN generate_series(s.start_date, LEAST(s.end_date, COALESCE(s.closed_at::date, CURRENT_DATE)), INTERVAL '1 day') d
+	          LEFT JOIN sprint_tasks st ON st.sprint_id = s.id
+	               AND st.added_at < d + INTERVAL '1 day'
+	               AND (st.removed_at IS NULL OR st.removed_at >= d + INTERVAL '1 day')
+	          LEFT JOIN tasks t ON t.id = st.task_id
+	          WHERE s.id = $1
+	          GROUP BY d
+	          ORDER BY d`
+	if err := h.db.Select(&response.Days, query, sprint.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	setIdealLine(sprint, response.Days)
+	respondReport(c, response, csvSection{"days", response.Days})
+}
+
+// setIdealLine draws a straight line from the first day's scope to zero on
+// the sprint's last day.
+func setIdealLine(sprint models.Sprint, days []BurndownDay) {
+	if len(days) == 0 {
+		return
+	}
+
+	start, errStart := time.Parse(time.RFC3339, sprint.StartDate)
+	end, errEnd := time.Parse(time.RFC3339, sprint.EndDate)
+	if errStart != nil || errEnd != nil {
+		return
+	}
+
+	total := end.Sub(start).Hours() / 24
+	for i := range days {
+		if total == 0 {
+			continue
+		}
+		elapsed := days[i].Date.Sub(start).Hours() / 24
+		days[i].Ideal = days[0].Scope * (1 - elapsed/total)
+	}
+}
+
+func (h *SprintHandler) load(c *gin.Context) (models.Sprint, bool) {
+	var sprint models.Sprint
+	if err := h.db.Get(&sprint, `SELECT * FROM sprints WHERE id = $1`, c.Param("id")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Sprint not found"})
+		return sprint, false
+	}
+	return sprint, true
+}
+
+var errTaskInOpenSprint = errors.New("task is already in an open sprint")
+
+func addSprintTask(tx *sqlx.Tx, sprint models.Sprint, taskID string) error {
+	var current string
+	query := `SELECT st.sprint_id FROM sprint_tasks st JOIN sprints s ON s.id = st.sprint_id
+	          WHERE st.task_id = $1 AND st.removed_at IS NULL AND s.status <> 'closed'`
+	err := tx.Get(&current, query, taskID)
+	if err == nil {
+		return errTaskInOpenSprint
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	insertQuery := `INSERT INTO sprint_tasks (sprint_id, task_id, committed) VALUES ($1, $2, $3)`
+	_, err = tx.Exec(insertQuery, sprint.ID, taskID, sprint.Status == "planned")
+	return err
+}
+
+func validateSprintDates(req SprintRequest) string {
+	start, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		return "start_date must be a date in YYYY-MM-DD format"
+	}
+	end, err := time.Parse("2006-01-02", req.EndDate)
+	if err != nil {
+		return "end_date must be a date in YYYY-MM-DD format"
+	}
+	if end.Before(start) {
+		return "end_date cannot be before start_date"
+	}
+	return ""
+}
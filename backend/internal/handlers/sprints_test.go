@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aalsa/management_dashboard/internal/models"
+)
+
+func TestSetIdealLine(t *testing.T) {
+	days := func(start string, scopes ...float64) []BurndownDay {
+		first, _ := time.Parse("2006-01-02", start)
+		result := make([]BurndownDay, len(scopes))
+		for i, scope := range scopes {
+			result[i] = BurndownDay{Date: first.AddDate(0, 0, i), Scope: scope}
+		}
+		return result
+	}
+	sprint := func(start, end string) models.Sprint {
+		return models.Sprint{StartDate: start + "T00:00:00Z", EndDate: end + "T00:00:00Z"}
+	}
+
+	tests := []struct {
+		name   string
+		sprint models.Sprint
+		days   []BurndownDay
+		want   []float64
+	}{
+		{"straight line", sprint("2024-03-04", "2024-03-08"), days("2024-03-04", 20, 20, 20, 20, 20),
+			[]float64{20, 15, 10, 5, 0}},
+		{"follows the first day's scope", sprint("2024-03-04", "2024-03-06"), days("2024-03-04", 10, 30, 30),
+			[]float64{10, 5, 0}},
+		{"sprint still running", sprint("2024-03-04", "2024-03-08"), days("2024-03-04", 8, 8),
+			[]float64{8, 6}},
+		{"one-day sprint", sprint("2024-03-04", "2024-03-04"), days("2024-03-04", 5), []float64{0}},
+		{"unparsable dates", models.Sprint{StartDate: "soon", EndDate: "later"}, days("2024-03-04", 5, 5),
+			[]float64{0, 0}},
+		{"no days", sprint("2024-03-04", "2024-03-08"), nil, []float64{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setIdealLine(tt.sprint, tt.days)
+			got := []float64{}
+			for _, day := range tt.days {
+				got = append(got, day.Ideal)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("setIdealLine() ideal = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateSprintDates(t *testing.T) {
+	tests := []struct {
+		name       string
+		start, end string
+		wantOK     bool
+	}{
+		{"two weeks", "2024-03-04", "2024-03-15", true},
+		{"same day", "2024-03-04", "2024-03-04", true},
+		{"end before start", "2024-03-15", "2024-03-04", false},
+		{"bad start", "03/04/2024", "2024-03-15", false},
+		{"bad end", "2024-03-04", "2024-02-30", false},
+		{"missing end", "2024-03-04", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problem := validateSprintDates(SprintRequest{StartDate: tt.start, EndDate: tt.end})
+			if (problem == "") != tt.wantOK {
+				t.Errorf("validateSprintDates(%q, %q) = %q, want ok %v", tt.start, tt.end, problem, tt.wantOK)
+			}
+		})
+	}
+}
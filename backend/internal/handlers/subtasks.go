@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/aalsa/management_dashboard/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+type SubtaskRollup struct {
+	TotalSubtasks     int     `db:"total_subtasks" json:"total_subtasks"`
+	CompletedSubtasks int     `db:"completed_subtasks" json:"completed_subtasks"`
+	PercentComplete   float64 `db:"-" json:"percent_complete"`
+	LoggedHours       float64 `db:"logged_hours" json:"logged_hours"`
+}
+
+type SubtasksResponse struct {
+	TaskID   string        `json:"task_id"`
+	Subtasks []models.Task `json:"subtasks"`
+	Rollup   SubtaskRollup `json:"rollup"`
+}
+
+// GetSubtasks lists the direct children of a task. The rollup covers every
+// descendant, and its logged hours include the parent's own time logs.
+func (h *TaskHandler) GetSubtasks(c *gin.Context) {
+	id := c.Param("id")
+
+	var exists bool
+	if err := h.db.Get(&exists, `SELECT EXISTS (SELECT 1 FROM tasks WHERE id = $1)`, id); err != nil || !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+
+	response := SubtasksResponse{TaskID: id, Subtasks: []models.Task{}}
+	query := `SELECT * FROM tasks WHERE parent_id = $1 ORDER BY due_date, created_at`
+	if err := h.db.Select(&response.Subtasks, query, id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	rollupQuery := `WITH RECURSIVE tree AS (
+	                    SELECT id, status FROM tasks WHERE parent_id = $1
+	                    UNION
+	                    SELECT t.id, t.status FROM tasks t JOIN tree ON t.parent_id = tree.id
+	                )
+	                SELECT COUNT(*) AS total_subtasks,
+	                       COUNT(*) FILTER (WHERE status = 'completed') AS completed_subtasks,
+	                       (SELECT COALESCE(SUM(hours), 0) FROM time_logs
+	                        WHERE task_id = $1 OR task_id IN (SELECT id FROM tree)) AS logged_hours
+	                FROM tree`
+	if err := h.db.Get(&response.Rollup, rollupQuery, id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if response.Rollup.TotalSubtasks > 0 {
+		response.Rollup.PercentComplete = float64(response.Rollup.CompletedSubtasks) * 100 / float64(response.Rollup.TotalSubtasks)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// checkParent returns a message describing why parentID cannot be the parent
+// of the task, or "" when it can.
+func checkParent(q sqlx.Queryer, taskID, projectID string, parentID *string) (string, error) {
+	if parentID == nil {
+		return "", nil
+	}
+	if *parentID == taskID {
+		return "A task cannot be its own parent", nil
+	}
+
+	var parentProject string
+	if err := sqlx.Get(q, &parentProject, `SELECT project_id FROM tasks WHERE id = $1`, *parentID); err != nil {
+		return "Parent task not found", nil
+	}
+	if parentProject != projectID {
+		return "Parent task must belong to the same project", nil
+	}
+
+	var cycle bool
+	query := `WITH RECURSIVE ancestors AS (
+	              SELECT id, parent_id FROM tasks WHERE id = $1
+	              UNION
+	              SELECT t.id, t.parent_id FROM tasks t JOIN ancestors a ON t.id = a.parent_id
+	          )
+	          SELECT EXISTS (SELECT 1 FROM ancestors WHERE id = $2)`
+	if err := sqlx.Get(q, &cycle, query, *parentID, taskID); err != nil {
+		return "", err
+	}
+	if cycle {
+		return "A task cannot be moved under one of its own subtasks", nil
+	}
+
+	return "", nil
+}
+
+// lockAndCheckParent serializes parent changes for the rest of tx, so two
+// concurrent moves cannot form a cycle, then runs checkParent.
+func lockAndCheckParent(tx *sqlx.Tx, taskID, projectID string, parentID *string) (string, error) {
+	if parentID == nil {
+		return "", nil
+	}
+	if _, err := tx.Exec(`LOCK TABLE tasks IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+		return "", err
+	}
+	return checkParent(tx, taskID, projectID, parentID)
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/aalsa/management_dashboard/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+func (h *TaskHandler) GetHistory(c *gin.Context) {
+	id := c.Param("id")
+
+	var exists bool
+	if err := h.db.Get(&exists, `SELECT EXISTS (SELECT 1 FROM tasks WHERE id = $1)`, id); err != nil || !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+
+	events := []models.TaskEvent{}
+	query := `SELECT te.*, u.email AS user_email
+	          FROM task_events te
+	          LEFT JOIN users u ON u.id = te.user_id
+	          WHERE te.task_id = $1
+	          ORDER BY te.created_at, te.field`
+
+	if err := h.db.Select(&events, query, id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, events)
+}
+
+// recordTaskEvents stores one event per tracked field that differs between
+// before and after. A nil before records the task's initial values.
+func recordTaskEvents(tx *sqlx.Tx, userID string, before *models.Task, after models.Task) error {
+	var old models.Task
+	if before != nil {
+		old = *before
+	}
+
+	changes := []struct {
+		field    string
+		old, new *string
+	}{
+		{"status", optional(old.Status), optional(after.Status)},
+		{"assigned_to", old.AssignedTo, after.AssignedTo},
+		{"priority", optional(old.Priority), optional(after.Priority)},
+	}
+
+	query := `INSERT INTO task_events (task_id, user_id, field, old_value, new_value) VALUES ($1, $2, $3, $4, $5)`
+	for _, change := range changes {
+		if sameValue(change.old, change.new) {
+			continue
+		}
+		if _, err := tx.Exec(query, after.ID, userID, change.field, change.old, change.new); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func optional(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+func sameValue(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -1,10 +1,14 @@
 package handlers
 
 import (
+	"encoding/json"
+	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/aalsa/management_dashboard/internal/models"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 )
@@ -17,16 +21,52 @@ func NewTaskHandler(db *sqlx.DB) *TaskHandler {
 	return &TaskHandler{db: db}
 }
 
+// TaskResponse is a saved task with warnings about its due date, such as the
+// assignee being on leave that day.
+type TaskResponse struct {
+	models.Task
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+var taskSortFields = map[string]string{
+	"title":           "title",
+	"status":          "status",
+	"priority":        "CASE priority WHEN 'high' THEN 1 WHEN 'medium' THEN 2 ELSE 3 END",
+	"due_date":        "due_date",
+	"story_points":    "story_points",
+	"estimated_hours": "estimated_hours",
+	"created_at":      "created_at",
+	"updated_at":      "updated_at",
+}
+
+func taskListQuery(c *gin.Context) *listQuery {
+	q := newListQuery(c, taskSortFields, "due_date, created_at DESC")
+	q.id("project_id", "project_id = ?")
+	q.id("assigned_to", "assigned_to = ?")
+	q.id("parent_id", "parent_id = ?")
+	q.oneOf("status", "status")
+	q.oneOf("priority", "priority")
+	q.date("due_from", "due_date >= ?")
+	q.date("due_to", "due_date <= ?")
+	return q
+}
+
 func (h *TaskHandler) GetAll(c *gin.Context) {
-	var tasks []models.Task
-	query := `SELECT * FROM tasks ORDER BY due_date, created_at DESC`
+	tasks := []models.Task{}
+	listPage(c, h.db, &tasks, "tasks", taskListQuery(c))
+}
 
-	if err := h.db.Select(&tasks, query); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+func (h *TaskHandler) GetMine(c *gin.Context) {
+	employeeID, ok := currentEmployee(c)
+	if !ok {
 		return
 	}
 
-	c.JSON(http.StatusOK, tasks)
+	q := taskListQuery(c)
+	q.where("assigned_to = ?", employeeID)
+
+	tasks := []models.Task{}
+	listPage(c, h.db, &tasks, "tasks", q)
 }
 
 func (h *TaskHandler) GetByID(c *gin.Context) {
@@ -51,56 +91,295 @@ func (h *TaskHandler) Create(c *gin.Context) {
 
 	task.ID = uuid.New().String()
 
-	query := `INSERT INTO tasks (id, title, description, project_id, assigned_to, status, priority, due_date)
-	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING created_at, updated_at`
+	tx, err := h.db.Beginx()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer tx.Rollback()
+
+	if status, message, err := checkTask(c, tx, nil, task); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	} else if status != 0 {
+		c.JSON(status, gin.H{"error": message})
+		return
+	}
+
+	if err := insertTask(tx, c.GetString("userID"), &task); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 
-	err := h.db.QueryRow(query, task.ID, task.Title, task.Description,
-		task.ProjectID, task.AssignedTo, task.Status, task.Priority, task.DueDate).
-		Scan(&task.CreatedAt, &task.UpdatedAt)
+	response := TaskResponse{Task: task}
+	if warning, err := leaveWarning(tx, task); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	} else if warning != "" {
+		response.Warnings = append(response.Warnings, warning)
+	}
 
-	if err != nil {
+	if err := tx.Commit(); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusCreated, task)
+	c.JSON(http.StatusCreated, response)
 }
 
 func (h *TaskHandler) Update(c *gin.Context) {
 	id := c.Param("id")
 	var task models.Task
 
-	if err := c.ShouldBindJSON(&task); err != nil {
+	if err := c.ShouldBindBodyWith(&task, binding.JSON); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var fields map[string]json.RawMessage
+	if err := c.ShouldBindBodyWith(&fields, binding.JSON); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	query := `UPDATE tasks SET title = $1, description = $2, assigned_to = $3,
-	          status = $4, priority = $5, due_date = $6, updated_at = CURRENT_TIMESTAMP
-	          WHERE id = $7`
+	var existing models.Task
+	if err := h.db.Get(&existing, `SELECT * FROM tasks WHERE id = $1`, id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
 
-	result, err := h.db.Exec(query, task.Title, task.Description, task.AssignedTo,
-		task.Status, task.Priority, task.DueDate, id)
+	task.ID = id
+	task.ProjectID = existing.ProjectID
+	// Clients that do not know about subtasks leave parent_id out; only an
+	// explicit null detaches the task.
+	if _, given := fields["parent_id"]; !given {
+		task.ParentID = existing.ParentID
+	}
 
+	tx, err := h.db.Beginx()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	defer tx.Rollback()
 
-	rows, _ := result.RowsAffected()
-	if rows == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+	if status, message, err := checkTask(c, tx, &existing, task); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	} else if status != 0 {
+		c.JSON(status, gin.H{"error": message})
+		return
+	}
+
+	if _, err := updateTask(tx, c.GetString("userID"), existing, task); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := gin.H{"message": "Task updated"}
+	if warning, err := leaveWarning(tx, task); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	} else if warning != "" {
+		response["warnings"] = []string{warning}
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Task updated"})
+	c.JSON(http.StatusOK, response)
+}
+
+var taskPriorities = map[string]bool{"low": true, "medium": true, "high": true}
+
+// checkTask applies the rules shared by Create, Update and the CSV import to
+// task, which replaces existing or is new when existing is nil. It runs in
+// the transaction that saves the task, so the import sees the rows it has
+// written and a parent change is checked under lock. It returns the HTTP
+// status and message for the first rule the task breaks.
+func checkTask(c *gin.Context, tx *sqlx.Tx, existing *models.Task, task models.Task) (int, string, error) {
+	if strings.TrimSpace(task.Title) == "" {
+		return http.StatusBadRequest, "title is required", nil
+	}
+	if !taskPriorities[task.Priority] {
+		return http.StatusBadRequest, "priority must be one of low, medium, high", nil
+	}
+	if task.DueDate != nil {
+		if _, err := parseDate(*task.DueDate); err != nil {
+			return http.StatusBadRequest, "due_date must be a date in YYYY-MM-DD format", nil
+		}
+	}
+	if task.StoryPoints != nil && *task.StoryPoints < 0 {
+		return http.StatusBadRequest, "story_points cannot be negative", nil
+	}
+	if task.EstimatedHours != nil && *task.EstimatedHours <= 0 {
+		return http.StatusBadRequest, "estimated_hours must be positive", nil
+	}
+	for _, ref := range []struct {
+		field string
+		value *string
+	}{{"project_id", &task.ProjectID}, {"assigned_to", task.AssignedTo}, {"parent_id", task.ParentID}} {
+		if ref.value == nil {
+			continue
+		}
+		if _, err := uuid.Parse(*ref.value); err != nil {
+			return http.StatusBadRequest, ref.field + " must be a valid id", nil
+		}
+	}
+
+	from := ""
+	if existing != nil {
+		from = existing.Status
+		if !canAccessTask(c, tx, existing.AssignedTo) {
+			return http.StatusForbidden, "You cannot manage tasks for this assignee", nil
+		}
+	}
+	if !canAccessTask(c, tx, task.AssignedTo) {
+		return http.StatusForbidden, "You cannot manage tasks for this assignee", nil
+	}
+
+	if task.AssignedTo != nil && (existing == nil || existing.AssignedTo == nil || *existing.AssignedTo != *task.AssignedTo) {
+		member, err := isProjectMember(tx, task.ProjectID, *task.AssignedTo)
+		if err != nil {
+			return 0, "", err
+		}
+		if !member {
+			return http.StatusUnprocessableEntity, "Assignee is not a member of the task's project", nil
+		}
+	}
+
+	// A new task has no subtasks, so only moving an existing one can form a
+	// cycle.
+	var problem string
+	var err error
+	if existing != nil && !sameValue(existing.ParentID, task.ParentID) {
+		problem, err = lockAndCheckParent(tx, task.ID, task.ProjectID, task.ParentID)
+	} else {
+		problem, err = checkParent(tx, task.ID, task.ProjectID, task.ParentID)
+	}
+	if err != nil {
+		return 0, "", err
+	} else if problem != "" {
+		return http.StatusUnprocessableEntity, problem, nil
+	}
+
+	if status, message, err := checkTaskStatus(tx, task.ProjectID, from, task.Status, c.GetString("role")); err != nil || status != 0 {
+		return status, message, err
+	}
+
+	// A task with open blockers can only move back to the workflow's first
+	// state or to one of its parking states. checkTaskStatus has already made
+	// sure the workflow has a transition for the move.
+	if existing != nil && task.Status != existing.Status {
+		workflow, err := loadWorkflow(tx, task.ProjectID)
+		if err != nil {
+			return 0, "", err
+		}
+		if blockedMoveAllowed(workflow, task.Status) {
+			return 0, "", nil
+		}
+
+		var openBlockers int
+		blockersQuery := `SELECT COUNT(*) FROM task_dependencies d
+		                  JOIN tasks b ON b.id = d.blocked_by_id
+		                  WHERE d.task_id = $1 AND b.status <> $2`
+		if err := sqlx.Get(tx, &openBlockers, blockersQuery, task.ID, completedState); err != nil {
+			return 0, "", err
+		}
+		if openBlockers > 0 {
+			return http.StatusConflict, fmt.Sprintf("Task is blocked by %d open task(s)", openBlockers), nil
+		}
+	}
+
+	return 0, "", nil
+}
+
+// insertTask creates the task with the ID already set and records its
+// initial history.
+func insertTask(tx *sqlx.Tx, userID string, task *models.Task) error {
+	// $7 is cast in both places so Postgres deduces a single type for it.
+	query := `INSERT INTO tasks (id, title, description, project_id, parent_id, assigned_to, status, priority, due_date,
+	          story_points, estimated_hours, completed_at)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7::varchar, $8, $9, $10, $11,
+	                  CASE WHEN $7::varchar = 'completed' THEN CURRENT_TIMESTAMP END)
+	          RETURNING created_at, updated_at, completed_at`
+
+	err := tx.QueryRow(query, task.ID, task.Title, task.Description, task.ProjectID, task.ParentID,
+		task.AssignedTo, task.Status, task.Priority, task.DueDate, task.StoryPoints, task.EstimatedHours).
+		Scan(&task.CreatedAt, &task.UpdatedAt, &task.CompletedAt)
+	if err != nil {
+		return err
+	}
+
+	return recordTaskEvents(tx, userID, nil, *task)
+}
+
+// updateTask overwrites existing with task's fields and records the changes.
+func updateTask(tx *sqlx.Tx, userID string, existing, task models.Task) (models.Task, error) {
+	// completed_at is kept while the task stays completed and cleared when it reopens.
+	query := `UPDATE tasks SET title = $1, description = $2, assigned_to = $3,
+	          status = $4, priority = $5, due_date = $6, parent_id = $7, story_points = $8, estimated_hours = $9,
+	          updated_at = CURRENT_TIMESTAMP,
+	          completed_at = CASE WHEN $4 = 'completed' THEN COALESCE(completed_at, CURRENT_TIMESTAMP) END
+	          WHERE id = $10 RETURNING *`
+
+	var updated models.Task
+	err := tx.Get(&updated, query, task.Title, task.Description, task.AssignedTo, task.Status, task.Priority,
+		task.DueDate, task.ParentID, task.StoryPoints, task.EstimatedHours, existing.ID)
+	if err != nil {
+		return updated, err
+	}
+
+	return updated, recordTaskEvents(tx, userID, &existing, updated)
 }
 
 func (h *TaskHandler) Delete(c *gin.Context) {
 	id := c.Param("id")
 
-	query := `DELETE FROM tasks WHERE id = $1`
-	result, err := h.db.Exec(query, id)
+	var existing models.Task
+	if err := h.db.Get(&existing, `SELECT * FROM tasks WHERE id = $1`, id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+
+	if !canAccessTask(c, h.db, existing.AssignedTo) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You cannot manage tasks for this assignee"})
+		return
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer tx.Rollback()
+
+	// The subtree is locked so no subtask can be added or reassigned between
+	// the access check and the delete.
+	var assignees []*string
+	query := `WITH RECURSIVE tree AS (
+	              SELECT id, assigned_to FROM tasks WHERE parent_id = $1
+	              UNION
+	              SELECT t.id, t.assigned_to FROM tasks t JOIN tree ON t.parent_id = tree.id
+	          )
+	          SELECT t.assigned_to FROM tasks t JOIN tree ON tree.id = t.id FOR UPDATE OF t`
+	if err := tx.Select(&assignees, query, id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if len(assignees) > 0 && c.Query("cascade") != "true" {
+		c.JSON(http.StatusConflict, gin.H{"error": "Task has subtasks; pass cascade=true to delete them as well"})
+		return
+	}
+	for _, assignee := range assignees {
+		if !canAccessTask(c, tx, assignee) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You cannot manage tasks for the assignee of a subtask"})
+			return
+		}
+	}
+
+	result, err := tx.Exec(`DELETE FROM tasks WHERE id = $1`, id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -112,5 +391,10 @@ func (h *TaskHandler) Delete(c *gin.Context) {
 		return
 	}
 
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{"message": "Task deleted"})
 }
@@ -0,0 +1,341 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/aalsa/management_dashboard/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+type TeamHandler struct {
+	db *sqlx.DB
+}
+
+func NewTeamHandler(db *sqlx.DB) *TeamHandler {
+	return &TeamHandler{db: db}
+}
+
+type TeamRequest struct {
+	Name         string  `json:"name" binding:"required,max=100"`
+	DepartmentID *string `json:"department_id"`
+	LeadID       *string `json:"lead_id"`
+}
+
+type TeamMemberRequest struct {
+	EmployeeID string `json:"employee_id" binding:"required"`
+}
+
+type TeamDetail struct {
+	models.Team
+	Members []models.TeamMember `json:"members"`
+}
+
+// teamsTable adds the member count to each team.
+const teamsTable = `(SELECT t.*,
+	(SELECT COUNT(*) FROM team_members tm WHERE tm.team_id = t.id) AS members
+	FROM teams t) AS teams`
+
+var teamSortFields = map[string]string{
+	"name":       "name",
+	"members":    "members",
+	"created_at": "created_at",
+}
+
+func (h *TeamHandler) GetAll(c *gin.Context) {
+	q := newListQuery(c, teamSortFields, "name")
+	q.text("name", "name ILIKE '%' || ? || '%'")
+	q.id("department_id", "department_id = ?")
+	q.id("employee_id", "id IN (SELECT team_id FROM team_members WHERE employee_id = ?)")
+
+	teams := []models.Team{}
+	listPage(c, h.db, &teams, teamsTable, q)
+}
+
+// GetByID returns the team with its members.
+func (h *TeamHandler) GetByID(c *gin.Context) {
+	var team TeamDetail
+	query := `SELECT * FROM ` + teamsTable + ` WHERE id = $1`
+	if err := h.db.Get(&team.Team, query, c.Param("id")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Team not found"})
+		return
+	}
+
+	team.Members = []models.TeamMember{}
+	query = `SELECT tm.employee_id, e.full_name, e.email, tm.created_at
+	         FROM team_members tm
+	         JOIN employees e ON e.id = tm.employee_id
+	         WHERE tm.team_id = $1
+	         ORDER BY e.full_name`
+	if err := h.db.Select(&team.Members, query, team.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, team)
+}
+
+func (h *TeamHandler) Create(c *gin.Context) {
+	var req TeamRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !canManageTeam(c, h.db, req.DepartmentID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only manage teams in your department"})
+		return
+	}
+
+	if status, problem, err := h.check("", req); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	} else if problem != "" {
+		c.JSON(status, gin.H{"error": problem})
+		return
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer tx.Rollback()
+
+	var team models.Team
+	query := `INSERT INTO teams (name, department_id, lead_id) VALUES ($1, $2, $3) RETURNING *, 0 AS members`
+	if err := tx.Get(&team, query, strings.TrimSpace(req.Name), req.DepartmentID, req.LeadID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if team.LeadID != nil {
+		if err := addTeamMember(tx, team.ID, *team.LeadID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		team.Members = 1
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, team)
+}
+
+func (h *TeamHandler) Update(c *gin.Context) {
+	id := c.Param("id")
+	var req TeamRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	team, ok := h.load(c)
+	if !ok {
+		return
+	}
+
+	if !canManageTeam(c, h.db, team.DepartmentID) || !canManageTeam(c, h.db, req.DepartmentID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only manage teams in your department"})
+		return
+	}
+
+	if status, problem, err := h.check(id, req); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	} else if problem != "" {
+		c.JSON(status, gin.H{"error": problem})
+		return
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE teams SET name = $1, department_id = $2, lead_id = $3, updated_at = CURRENT_TIMESTAMP
+	          WHERE id = $4`
+	if _, err := tx.Exec(query, strings.TrimSpace(req.Name), req.DepartmentID, req.LeadID, id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.LeadID != nil {
+		if err := addTeamMember(tx, id, *req.LeadID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Team updated"})
+}
+
+func (h *TeamHandler) Delete(c *gin.Context) {
+	team, ok := h.load(c)
+	if !ok {
+		return
+	}
+
+	if !canManageTeam(c, h.db, team.DepartmentID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only manage teams in your department"})
+		return
+	}
+
+	if _, err := h.db.Exec(`DELETE FROM teams WHERE id = $1`, team.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Team deleted"})
+}
+
+// AddMember adds an employee from any department to the team. Adding an
+// existing member is a no-op.
+func (h *TeamHandler) AddMember(c *gin.Context) {
+	var req TeamMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	team, ok := h.load(c)
+	if !ok {
+		return
+	}
+
+	if !canManageTeam(c, h.db, team.DepartmentID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only manage teams in your department"})
+		return
+	}
+
+	var exists bool
+	if err := h.db.Get(&exists, `SELECT EXISTS (SELECT 1 FROM employees WHERE id::text = $1)`, req.EmployeeID); err != nil || !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Employee not found"})
+		return
+	}
+
+	if err := addTeamMember(h.db, team.ID, req.EmployeeID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Team member added"})
+}
+
+// RemoveMember takes the employee off the team. Removing the lead also clears
+// the team's lead.
+func (h *TeamHandler) RemoveMember(c *gin.Context) {
+	employeeID := c.Param("employeeId")
+
+	team, ok := h.load(c)
+	if !ok {
+		return
+	}
+
+	if !canManageTeam(c, h.db, team.DepartmentID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only manage teams in your department"})
+		return
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`DELETE FROM team_members WHERE team_id = $1 AND employee_id = $2`, team.ID, employeeID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Team member not found"})
+		return
+	}
+
+	query := `UPDATE teams SET lead_id = NULL, updated_at = CURRENT_TIMESTAMP WHERE id = $1 AND lead_id = $2`
+	if _, err := tx.Exec(query, team.ID, employeeID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Team member removed"})
+}
+
+func (h *TeamHandler) load(c *gin.Context) (models.Team, bool) {
+	var team models.Team
+	query := `SELECT * FROM ` + teamsTable + ` WHERE id = $1`
+	if err := h.db.Get(&team, query, c.Param("id")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Team not found"})
+		return team, false
+	}
+	return team, true
+}
+
+// check rejects blank or duplicate names (ignoring case) and references to a
+// missing department or lead. id is empty for new teams.
+func (h *TeamHandler) check(id string, req TeamRequest) (int, string, error) {
+	if strings.TrimSpace(req.Name) == "" {
+		return http.StatusBadRequest, "name is required", nil
+	}
+
+	var taken bool
+	query := `SELECT EXISTS (SELECT 1 FROM teams WHERE lower(name) = lower($1) AND id::text <> $2)`
+	if err := h.db.Get(&taken, query, strings.TrimSpace(req.Name), id); err != nil {
+		return 0, "", err
+	}
+	if taken {
+		return http.StatusConflict, "A team with this name already exists", nil
+	}
+
+	if req.DepartmentID != nil {
+		var exists bool
+		query := `SELECT EXISTS (SELECT 1 FROM departments WHERE id::text = $1)`
+		if err := h.db.Get(&exists, query, *req.DepartmentID); err != nil {
+			return 0, "", err
+		}
+		if !exists {
+			return http.StatusBadRequest, "Department not found", nil
+		}
+	}
+
+	if problem, err := checkEmployeeRef(h.db, req.LeadID, "Lead"); err != nil || problem != "" {
+		return http.StatusBadRequest, problem, err
+	}
+	return 0, "", nil
+}
+
+// canManageTeam applies the department rules to the team's department. Teams
+// outside any department can only be managed by admins.
+func canManageTeam(c *gin.Context, db *sqlx.DB, departmentID *string) bool {
+	if departmentID == nil {
+		return c.GetString("role") == models.RoleAdmin
+	}
+	return canAccessDepartment(c, db, *departmentID)
+}
+
+func addTeamMember(db sqlx.Execer, teamID, employeeID string) error {
+	query := `INSERT INTO team_members (team_id, employee_id) VALUES ($1, $2)
+	          ON CONFLICT (team_id, employee_id) DO NOTHING`
+	_, err := db.Exec(query, teamID, employeeID)
+	return err
+}
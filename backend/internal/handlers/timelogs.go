@@ -17,16 +17,39 @@ func NewTimeLogHandler(db *sqlx.DB) *TimeLogHandler {
 	return &TimeLogHandler{db: db}
 }
 
+var timeLogSortFields = map[string]string{
+	"log_date":   "log_date",
+	"hours":      "hours",
+	"created_at": "created_at",
+}
+
+func timeLogListQuery(c *gin.Context) *listQuery {
+	q := newListQuery(c, timeLogSortFields, "log_date DESC, created_at DESC")
+	q.id("employee_id", "employee_id = ?")
+	q.id("task_id", "task_id = ?")
+	q.date("from", "log_date >= ?")
+	q.date("to", "log_date <= ?")
+	q.boolean("billable", "billable = ?")
+	q.id("invoice_id", "invoice_id = ?")
+	return q
+}
+
 func (h *TimeLogHandler) GetAll(c *gin.Context) {
-	var logs []models.TimeLog
-	query := `SELECT * FROM time_logs ORDER BY log_date DESC, created_at DESC`
+	logs := []models.TimeLog{}
+	listPage(c, h.db, &logs, "time_logs", timeLogListQuery(c))
+}
 
-	if err := h.db.Select(&logs, query); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+func (h *TimeLogHandler) GetMine(c *gin.Context) {
+	employeeID, ok := currentEmployee(c)
+	if !ok {
 		return
 	}
 
-	c.JSON(http.StatusOK, logs)
+	q := timeLogListQuery(c)
+	q.where("employee_id = ?", employeeID)
+
+	logs := []models.TimeLog{}
+	listPage(c, h.db, &logs, "time_logs", q)
 }
 
 func (h *TimeLogHandler) GetByID(c *gin.Context) {
@@ -49,17 +72,53 @@ func (h *TimeLogHandler) Create(c *gin.Context) {
 		return
 	}
 
+	if !canAccessEmployee(c, h.db, log.EmployeeID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You cannot manage time logs for this employee"})
+		return
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer tx.Rollback()
+
+	locked, err := weekLocked(tx, log.EmployeeID, log.LogDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if locked {
+		c.JSON(http.StatusConflict, gin.H{"error": weekLockedMessage})
+		return
+	}
+
+	if leave, err := onLeave(tx, log.EmployeeID, log.LogDate); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	} else if leave {
+		c.JSON(http.StatusConflict, gin.H{"error": onLeaveMessage})
+		return
+	}
+
 	log.ID = uuid.New().String()
+	log.InvoiceID = nil
 
-	query := `INSERT INTO time_logs (id, employee_id, task_id, hours, log_date, notes)
-	          VALUES ($1, $2, $3, $4, $5, $6) RETURNING created_at`
+	query := `INSERT INTO time_logs (id, employee_id, task_id, hours, log_date, notes, billable)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING created_at`
 
-	err := h.db.QueryRow(query, log.ID, log.EmployeeID, log.TaskID, log.Hours, log.LogDate, log.Notes).Scan(&log.CreatedAt)
+	err = tx.QueryRow(query, log.ID, log.EmployeeID, log.TaskID, log.Hours, log.LogDate, log.Notes, log.Billable).Scan(&log.CreatedAt)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
 	c.JSON(http.StatusCreated, log)
 }
 
@@ -72,8 +131,55 @@ func (h *TimeLogHandler) Update(c *gin.Context) {
 		return
 	}
 
-	query := `UPDATE time_logs SET hours = $1, log_date = $2, notes = $3 WHERE id = $4`
-	result, err := h.db.Exec(query, log.Hours, log.LogDate, log.Notes, id)
+	var existing models.TimeLog
+	if err := h.db.Get(&existing, `SELECT * FROM time_logs WHERE id = $1`, id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Time log not found"})
+		return
+	}
+
+	if !canAccessEmployee(c, h.db, existing.EmployeeID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You cannot manage time logs for this employee"})
+		return
+	}
+
+	// The week checks lock the timesheets involved until the update is
+	// saved, so neither week can be submitted in between.
+	tx, err := h.db.Beginx()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer tx.Rollback()
+
+	locked, err := timeLogLocked(tx, id)
+	if err == nil && !locked {
+		locked, err = weekLocked(tx, existing.EmployeeID, log.LogDate)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if locked {
+		c.JSON(http.StatusConflict, gin.H{"error": weekLockedMessage})
+		return
+	}
+
+	if leave, err := onLeave(tx, existing.EmployeeID, log.LogDate); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	} else if leave {
+		c.JSON(http.StatusConflict, gin.H{"error": onLeaveMessage})
+		return
+	}
+
+	if existing.InvoiceID != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": invoicedMessage})
+		return
+	}
+
+	query := `UPDATE time_logs SET hours = $1, log_date = $2, notes = $3, billable = $4
+	          WHERE id = $5 AND invoice_id IS NULL`
+	result, err := tx.Exec(query, log.Hours, log.LogDate, log.Notes, log.Billable, id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -85,14 +191,52 @@ func (h *TimeLogHandler) Update(c *gin.Context) {
 		return
 	}
 
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{"message": "Time log updated"})
 }
 
 func (h *TimeLogHandler) Delete(c *gin.Context) {
 	id := c.Param("id")
 
-	query := `DELETE FROM time_logs WHERE id = $1`
-	result, err := h.db.Exec(query, id)
+	var existing models.TimeLog
+	if err := h.db.Get(&existing, `SELECT * FROM time_logs WHERE id = $1`, id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Time log not found"})
+		return
+	}
+
+	if !canAccessEmployee(c, h.db, existing.EmployeeID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You cannot manage time logs for this employee"})
+		return
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer tx.Rollback()
+
+	locked, err := timeLogLocked(tx, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if locked {
+		c.JSON(http.StatusConflict, gin.H{"error": weekLockedMessage})
+		return
+	}
+
+	if existing.InvoiceID != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": invoicedMessage})
+		return
+	}
+
+	query := `DELETE FROM time_logs WHERE id = $1 AND invoice_id IS NULL`
+	result, err := tx.Exec(query, id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -104,33 +248,44 @@ func (h *TimeLogHandler) Delete(c *gin.Context) {
 		return
 	}
 
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{"message": "Time log deleted"})
 }
 
-func (h *TimeLogHandler) GetEmployeeHours(c *gin.Context) {
-	employeeID := c.Param("id")
+type EmployeeHours struct {
+	EmployeeID string  `json:"employee_id"`
+	TotalHours float64 `json:"total_hours"`
+}
+
+type TaskHours struct {
+	TaskID     string  `json:"task_id"`
+	TotalHours float64 `json:"total_hours"`
+}
 
-	var total float64
+func (h *TimeLogHandler) GetEmployeeHours(c *gin.Context) {
+	hours := EmployeeHours{EmployeeID: c.Param("id")}
 	query := `SELECT COALESCE(SUM(hours), 0) FROM time_logs WHERE employee_id = $1`
 
-	if err := h.db.Get(&total, query, employeeID); err != nil {
+	if err := h.db.Get(&hours.TotalHours, query, hours.EmployeeID); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"employee_id": employeeID, "total_hours": total})
+	respondReport(c, hours, csvSection{"hours", []EmployeeHours{hours}})
 }
 
 func (h *TimeLogHandler) GetTaskHours(c *gin.Context) {
-	taskID := c.Param("id")
-
-	var total float64
+	hours := TaskHours{TaskID: c.Param("id")}
 	query := `SELECT COALESCE(SUM(hours), 0) FROM time_logs WHERE task_id = $1`
 
-	if err := h.db.Get(&total, query, taskID); err != nil {
+	if err := h.db.Get(&hours.TotalHours, query, hours.TaskID); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"task_id": taskID, "total_hours": total})
+	respondReport(c, hours, csvSection{"hours", []TaskHours{hours}})
 }
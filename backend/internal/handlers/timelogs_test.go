@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aalsa/management_dashboard/internal/models"
+)
+
+func TestCreateTimeLogOnLeave(t *testing.T) {
+	tests := []struct {
+		name       string
+		onLeave    bool
+		wantStatus int
+	}{
+		{"working day", false, http.StatusCreated},
+		{"approved leave", true, http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			steps := []fakeStep{
+				{match: "FROM timesheets", columns: []string{"locked"}, rows: [][]driver.Value{{false}}},
+				{match: "FROM leave_requests", columns: []string{"exists"}, rows: [][]driver.Value{{tt.onLeave}}},
+			}
+			if !tt.onLeave {
+				steps = append(steps, fakeStep{match: "INSERT INTO time_logs", columns: []string{"created_at"},
+					rows: [][]driver.Value{{time.Now()}}})
+			}
+			db, f := newFakeDB(t, steps...)
+
+			body := `{"employee_id": "e1", "task_id": "t1", "hours": 2, "log_date": "2026-03-03"}`
+			w := serve(NewTimeLogHandler(db).Create, body, nil, "role", models.RoleAdmin)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Create() = %d %s, want %d", w.Code, w.Body, tt.wantStatus)
+			}
+			if date := f.args[1][1]; date != "2026-03-03" {
+				t.Errorf("leave checked for %v, want the log date", date)
+			}
+		})
+	}
+}
@@ -0,0 +1,309 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aalsa/management_dashboard/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type TimerHandler struct {
+	db              *sqlx.DB
+	roundingMinutes int
+	roundingMode    string
+	maxHours        float64
+	// lockedTimers holds the employees whose expired timer is kept because
+	// its week is locked, so StopExpired reports each one only once. It is
+	// guarded by expiredMu.
+	expiredMu    sync.Mutex
+	lockedTimers map[string]bool
+}
+
+func NewTimerHandler(db *sqlx.DB) *TimerHandler {
+	h := &TimerHandler{db: db, roundingMinutes: 15, roundingMode: "nearest", maxHours: 10}
+
+	if minutes, err := strconv.Atoi(os.Getenv("TIMER_ROUNDING_MINUTES")); err == nil && minutes > 0 {
+		h.roundingMinutes = minutes
+	}
+	switch mode := os.Getenv("TIMER_ROUNDING_MODE"); mode {
+	case "up", "down", "nearest":
+		h.roundingMode = mode
+	}
+	if hours, err := strconv.ParseFloat(os.Getenv("TIMER_MAX_HOURS"), 64); err == nil && hours > 0 {
+		h.maxHours = hours
+	}
+
+	return h
+}
+
+type StartTimerRequest struct {
+	TaskID   string `json:"task_id" binding:"required"`
+	Notes    string `json:"notes"`
+	Billable bool   `json:"billable"`
+}
+
+type StopTimerRequest struct {
+	Notes    *string `json:"notes"`
+	Billable *bool   `json:"billable"`
+}
+
+type TimerResponse struct {
+	models.Timer
+	ElapsedSeconds float64 `db:"elapsed_seconds" json:"elapsed_seconds"`
+}
+
+func (h *TimerHandler) Start(c *gin.Context) {
+	employeeID, ok := currentEmployee(c)
+	if !ok {
+		return
+	}
+
+	var req StartTimerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.stopExpired(employeeID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var exists bool
+	if err := h.db.Get(&exists, `SELECT EXISTS (SELECT 1 FROM tasks WHERE id = $1)`, req.TaskID); err != nil || !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+
+	today := time.Now().Format("2006-01-02")
+	locked, err := weekLocked(h.db, employeeID, today)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if locked {
+		c.JSON(http.StatusConflict, gin.H{"error": weekLockedMessage})
+		return
+	}
+
+	if leave, err := onLeave(h.db, employeeID, today); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	} else if leave {
+		c.JSON(http.StatusConflict, gin.H{"error": onLeaveMessage})
+		return
+	}
+
+	timer := models.Timer{
+		ID:         uuid.New().String(),
+		EmployeeID: employeeID,
+		TaskID:     req.TaskID,
+		Notes:      req.Notes,
+		Billable:   req.Billable,
+	}
+
+	query := `INSERT INTO timers (id, employee_id, task_id, notes, billable) VALUES ($1, $2, $3, $4, $5)
+	          ON CONFLICT (employee_id) DO NOTHING RETURNING started_at`
+	err = h.db.QueryRow(query, timer.ID, timer.EmployeeID, timer.TaskID, timer.Notes, timer.Billable).Scan(&timer.StartedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusConflict, gin.H{"error": "A timer is already running"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, timer)
+}
+
+func (h *TimerHandler) Stop(c *gin.Context) {
+	employeeID, ok := currentEmployee(c)
+	if !ok {
+		return
+	}
+
+	var req StopTimerRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	timeLog, err := h.stop(employeeID, req, false)
+	if errors.Is(err, errWeekLocked) {
+		c.JSON(http.StatusConflict, gin.H{"error": weekLockedMessage + "; the timer keeps running until the week is reopened"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if timeLog == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No timer is running"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, timeLog)
+}
+
+func (h *TimerHandler) GetCurrent(c *gin.Context) {
+	employeeID, ok := currentEmployee(c)
+	if !ok {
+		return
+	}
+
+	if err := h.stopExpired(employeeID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var timer TimerResponse
+	query := `SELECT *, EXTRACT(EPOCH FROM (CURRENT_TIMESTAMP - started_at)) AS elapsed_seconds
+	          FROM timers WHERE employee_id = $1`
+	if err := h.db.Get(&timer, query, employeeID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No timer is running"})
+		return
+	}
+
+	c.JSON(http.StatusOK, timer)
+}
+
+// StopExpired stops every timer that has run past the maximum duration,
+// logging exactly the maximum. A timer that fails to stop does not hold up
+// the others; the errors are returned together. Concurrent calls run one at
+// a time.
+func (h *TimerHandler) StopExpired() error {
+	h.expiredMu.Lock()
+	defer h.expiredMu.Unlock()
+
+	var employeeIDs []string
+	query := `SELECT employee_id FROM timers WHERE started_at < CURRENT_TIMESTAMP - $1 * INTERVAL '1 hour'`
+	if err := h.db.Select(&employeeIDs, query, h.maxHours); err != nil {
+		return err
+	}
+
+	var errs []error
+	lockedTimers := map[string]bool{}
+	for _, employeeID := range employeeIDs {
+		_, err := h.stop(employeeID, StopTimerRequest{}, true)
+		if errors.Is(err, errWeekLocked) {
+			if !h.lockedTimers[employeeID] {
+				log.Printf("Timer for employee %s is past %.1f hours but its week is locked; left running", employeeID, h.maxHours)
+			}
+			lockedTimers[employeeID] = true
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("employee %s: %w", employeeID, err))
+			continue
+		}
+		log.Printf("Auto-stopped timer for employee %s after %.1f hours", employeeID, h.maxHours)
+	}
+	h.lockedTimers = lockedTimers
+	return errors.Join(errs...)
+}
+
+// stopExpired stops the employee's timer if it has run past the maximum
+// duration, so their own requests never see it before the ticker's
+// StopExpired does. A timer kept for a locked week is left to StopExpired
+// to report.
+func (h *TimerHandler) stopExpired(employeeID string) error {
+	timeLog, err := h.stop(employeeID, StopTimerRequest{}, true)
+	if errors.Is(err, errWeekLocked) {
+		return nil
+	}
+	if timeLog != nil {
+		log.Printf("Auto-stopped timer for employee %s after %.1f hours", employeeID, h.maxHours)
+	}
+	return err
+}
+
+// stop removes the employee's running timer and records it as a time log,
+// with the notes and billable flag in req overriding the timer's when given.
+// With expiredOnly it only stops a timer past the maximum duration. It
+// returns nil when there is no such timer.
+func (h *TimerHandler) stop(employeeID string, req StopTimerRequest, expiredOnly bool) (*models.TimeLog, error) {
+	tx, err := h.db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var timer TimerResponse
+	query := `DELETE FROM timers
+	          WHERE employee_id = $1 AND (NOT $3 OR started_at < CURRENT_TIMESTAMP - $2 * INTERVAL '1 hour')
+	          RETURNING *, EXTRACT(EPOCH FROM LEAST(CURRENT_TIMESTAMP - started_at, $2 * INTERVAL '1 hour')) AS elapsed_seconds`
+	if err := tx.Get(&timer, query, employeeID, h.maxHours, expiredOnly); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	// A timer in a locked week cannot be logged, so it is kept by rolling back
+	// until the week is reopened.
+	locked, err := weekLocked(tx, timer.EmployeeID, timer.StartedAt.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	if locked {
+		return nil, errWeekLocked
+	}
+
+	timeLog := models.TimeLog{
+		ID:         uuid.New().String(),
+		EmployeeID: timer.EmployeeID,
+		TaskID:     timer.TaskID,
+		Hours:      h.roundHours(timer.ElapsedSeconds),
+		LogDate:    timer.StartedAt.Format("2006-01-02"),
+		Notes:      timer.Notes,
+		Billable:   timer.Billable,
+	}
+	if req.Notes != nil {
+		timeLog.Notes = *req.Notes
+	}
+	if req.Billable != nil {
+		timeLog.Billable = *req.Billable
+	}
+
+	insertQuery := `INSERT INTO time_logs (id, employee_id, task_id, hours, log_date, notes, billable)
+	                VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING created_at`
+	err = tx.QueryRow(insertQuery, timeLog.ID, timeLog.EmployeeID, timeLog.TaskID,
+		timeLog.Hours, timeLog.LogDate, timeLog.Notes, timeLog.Billable).Scan(&timeLog.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &timeLog, tx.Commit()
+}
+
+// roundHours rounds elapsed time to the configured increment. Anything short
+// of one increment still counts as one so a stopped timer always logs time.
+func (h *TimerHandler) roundHours(seconds float64) float64 {
+	increment := float64(h.roundingMinutes * 60)
+	units := seconds / increment
+
+	switch h.roundingMode {
+	case "up":
+		units = math.Ceil(units)
+	case "down":
+		units = math.Floor(units)
+	default:
+		units = math.Round(units)
+	}
+
+	return math.Max(units, 1) * increment / 3600
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRoundHours(t *testing.T) {
+	tests := []struct {
+		name    string
+		minutes int
+		mode    string
+		seconds float64
+		want    float64
+	}{
+		{"nearest rounds down", 15, "nearest", 20 * 60, 0.25},
+		{"nearest rounds up", 15, "nearest", 38 * 60, 0.75},
+		{"nearest exact", 15, "nearest", 90 * 60, 1.5},
+		{"up", 15, "up", 61 * 60, 1.25},
+		{"down", 15, "down", 74 * 60, 1},
+		{"minimum of one increment", 15, "down", 60, 0.25},
+		{"zero elapsed", 6, "nearest", 0, 0.1},
+		{"hour increments", 60, "nearest", 150 * 60, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &TimerHandler{roundingMinutes: tt.minutes, roundingMode: tt.mode}
+			if got := h.roundHours(tt.seconds); got != tt.want {
+				t.Errorf("roundHours(%v) = %v, want %v", tt.seconds, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStartTimer(t *testing.T) {
+	insert := fakeStep{match: "INSERT INTO timers", columns: []string{"started_at"},
+		rows: [][]driver.Value{{time.Now()}}}
+	running := insert
+	running.rows = nil
+	taskGone := insert
+	taskGone.rows, taskGone.err = nil, errors.New("violates foreign key constraint")
+
+	tests := []struct {
+		name       string
+		insert     fakeStep
+		wantStatus int
+	}{
+		{"started", insert, http.StatusCreated},
+		{"already running", running, http.StatusConflict},
+		{"insert fails", taskGone, http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, f := newFakeDB(t,
+				fakeStep{match: "DELETE FROM timers"},
+				fakeStep{match: "FROM tasks", columns: []string{"exists"}, rows: [][]driver.Value{{true}}},
+				fakeStep{match: "FROM timesheets", columns: []string{"locked"}, rows: [][]driver.Value{{false}}},
+				fakeStep{match: "FROM leave_requests", columns: []string{"exists"}, rows: [][]driver.Value{{false}}},
+				tt.insert,
+			)
+
+			w := serve(NewTimerHandler(db).Start, `{"task_id": "t1"}`, nil, "employeeID", "e1")
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Start() = %d %s, want %d", w.Code, w.Body, tt.wantStatus)
+			}
+			if employee, expiredOnly := f.args[0][0], f.args[0][2]; employee != "e1" || expiredOnly != true {
+				t.Errorf("stopped timers of %v (expired only %v), want only the caller's expired one", employee, expiredOnly)
+			}
+		})
+	}
+}
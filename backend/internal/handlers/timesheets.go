@@ -0,0 +1,278 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/aalsa/management_dashboard/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+var errWeekLocked = errors.New("week is locked by its timesheet")
+
+const weekLockedMessage = "This week's timesheet is submitted or approved and can no longer be changed"
+
+// timesheetsTable adds the week's logged hours to each timesheet row, with
+// the capacity the employee's leave and holidays take out of the week and
+// the hours they are expected to log.
+const timesheetsTable = `(SELECT t.*,
+	(SELECT COALESCE(SUM(l.hours), 0) FROM time_logs l
+	 WHERE l.employee_id = t.employee_id AND l.log_date >= t.week_start AND l.log_date < t.week_start + 7) AS total_hours,
+	off.leave_hours,
+	e.weekly_capacity_hours - off.leave_hours AS expected_hours
+	FROM timesheets t
+	JOIN employees e ON e.id = t.employee_id
+	CROSS JOIN LATERAL (SELECT ROUND(e.weekly_capacity_hours * LEAST(COUNT(*), 5) / 5, 2) AS leave_hours
+	                    FROM ` + daysOffTable + `
+	                    WHERE days_off.employee_id = t.employee_id
+	                      AND days_off.day >= t.week_start AND days_off.day < t.week_start + 7) off
+	) AS timesheets`
+
+type TimesheetHandler struct {
+	db *sqlx.DB
+}
+
+func NewTimesheetHandler(db *sqlx.DB) *TimesheetHandler {
+	return &TimesheetHandler{db: db}
+}
+
+type CreateTimesheetRequest struct {
+	EmployeeID string `json:"employee_id"`
+	WeekStart  string `json:"week_start" binding:"required"`
+}
+
+type RejectTimesheetRequest struct {
+	Comment string `json:"comment" binding:"required"`
+}
+
+type TimesheetDetail struct {
+	models.Timesheet
+	TimeLogs []models.TimeLog `json:"time_logs"`
+}
+
+var timesheetSortFields = map[string]string{
+	"week_start":   "week_start",
+	"status":       "status",
+	"submitted_at": "submitted_at",
+}
+
+// GetAll lists the timesheets of the employees the caller may see; see
+// scopeToEmployees.
+func (h *TimesheetHandler) GetAll(c *gin.Context) {
+	q := newListQuery(c, timesheetSortFields, "week_start DESC")
+	scopeToEmployees(c, q, "employee_id")
+	q.id("employee_id", "employee_id = ?")
+	q.oneOf("status", "status")
+	q.date("from", "week_start >= ?")
+	q.date("to", "week_start <= ?")
+
+	timesheets := []models.Timesheet{}
+	listPage(c, h.db, &timesheets, timesheetsTable, q)
+}
+
+func (h *TimesheetHandler) GetByID(c *gin.Context) {
+	id := c.Param("id")
+	var detail TimesheetDetail
+
+	query := `SELECT * FROM ` + timesheetsTable + ` WHERE id = $1`
+	if err := h.db.Get(&detail.Timesheet, query, id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Timesheet not found"})
+		return
+	}
+
+	if !canAccessEmployee(c, h.db, detail.EmployeeID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You cannot view timesheets for this employee"})
+		return
+	}
+
+	detail.TimeLogs = []models.TimeLog{}
+	logsQuery := `SELECT l.* FROM time_logs l
+	              JOIN timesheets t ON t.employee_id = l.employee_id
+	              WHERE t.id = $1 AND l.log_date >= t.week_start AND l.log_date < t.week_start + 7
+	              ORDER BY l.log_date, l.created_at`
+	if err := h.db.Select(&detail.TimeLogs, logsQuery, id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, detail)
+}
+
+func (h *TimesheetHandler) Create(c *gin.Context) {
+	var req CreateTimesheetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := time.Parse("2006-01-02", req.WeekStart); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "week_start must be a date in YYYY-MM-DD format"})
+		return
+	}
+
+	if req.EmployeeID == "" {
+		employeeID, ok := currentEmployee(c)
+		if !ok {
+			return
+		}
+		req.EmployeeID = employeeID
+	}
+
+	if !canAccessEmployee(c, h.db, req.EmployeeID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You cannot manage timesheets for this employee"})
+		return
+	}
+
+	// Any date in the week is accepted and normalized to its Monday.
+	query := `INSERT INTO timesheets (id, employee_id, week_start)
+	          VALUES ($1, $2, date_trunc('week', $3::date)::date)
+	          ON CONFLICT (employee_id, week_start) DO UPDATE SET updated_at = timesheets.updated_at
+	          RETURNING id`
+	var id string
+	if err := h.db.Get(&id, query, uuid.New().String(), req.EmployeeID, req.WeekStart); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var timesheet models.Timesheet
+	if err := h.db.Get(&timesheet, `SELECT * FROM `+timesheetsTable+` WHERE id = $1`, id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, timesheet)
+}
+
+func (h *TimesheetHandler) Submit(c *gin.Context) {
+	timesheet, ok := h.load(c)
+	if !ok {
+		return
+	}
+
+	if !canAccessEmployee(c, h.db, timesheet.EmployeeID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You cannot manage timesheets for this employee"})
+		return
+	}
+
+	// A running timer would log into the week once stopped, so it has to be
+	// stopped first.
+	var timing bool
+	timerQuery := `SELECT EXISTS (SELECT 1 FROM timers t JOIN timesheets s ON s.employee_id = t.employee_id
+	               WHERE s.id = $1 AND t.started_at::date >= s.week_start AND t.started_at::date < s.week_start + 7)`
+	if err := h.db.Get(&timing, timerQuery, timesheet.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if timing {
+		c.JSON(http.StatusConflict, gin.H{"error": "Stop the timer running in this week before submitting it"})
+		return
+	}
+
+	query := `UPDATE timesheets SET status = 'submitted', submitted_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+	          WHERE id = $1 AND status IN ('draft', 'rejected')`
+	h.transition(c, timesheet.ID, "Only draft or rejected timesheets can be submitted", query, timesheet.ID)
+}
+
+func (h *TimesheetHandler) Approve(c *gin.Context) {
+	timesheet, ok := h.load(c)
+	if !ok {
+		return
+	}
+
+	if !canReview(c, h.db, timesheet.EmployeeID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the employee's manager can review this timesheet"})
+		return
+	}
+
+	query := `UPDATE timesheets SET status = 'approved', reviewed_by = $2, reviewed_at = CURRENT_TIMESTAMP,
+	          review_comment = '', updated_at = CURRENT_TIMESTAMP
+	          WHERE id = $1 AND status = 'submitted'`
+	h.transition(c, timesheet.ID, "Only submitted timesheets can be approved", query, timesheet.ID, c.GetString("userID"))
+}
+
+// Reject reopens the week for editing. Approved weeks can be rejected too so
+// payroll corrections remain possible.
+func (h *TimesheetHandler) Reject(c *gin.Context) {
+	timesheet, ok := h.load(c)
+	if !ok {
+		return
+	}
+
+	var req RejectTimesheetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !canReview(c, h.db, timesheet.EmployeeID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the employee's manager can review this timesheet"})
+		return
+	}
+
+	query := `UPDATE timesheets SET status = 'rejected', reviewed_by = $2, reviewed_at = CURRENT_TIMESTAMP,
+	          review_comment = $3, updated_at = CURRENT_TIMESTAMP
+	          WHERE id = $1 AND status IN ('submitted', 'approved')`
+	h.transition(c, timesheet.ID, "Only submitted or approved timesheets can be rejected",
+		query, timesheet.ID, c.GetString("userID"), req.Comment)
+}
+
+func (h *TimesheetHandler) load(c *gin.Context) (models.Timesheet, bool) {
+	var timesheet models.Timesheet
+	query := `SELECT * FROM ` + timesheetsTable + ` WHERE id = $1`
+	if err := h.db.Get(&timesheet, query, c.Param("id")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Timesheet not found"})
+		return timesheet, false
+	}
+	return timesheet, true
+}
+
+func (h *TimesheetHandler) transition(c *gin.Context, id, conflict, query string, args ...interface{}) {
+	result, err := h.db.Exec(query, args...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": conflict})
+		return
+	}
+
+	var timesheet models.Timesheet
+	if err := h.db.Get(&timesheet, `SELECT * FROM `+timesheetsTable+` WHERE id = $1`, id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, timesheet)
+}
+
+// weekLocked reports whether the week containing date is submitted or approved
+// for the employee, in which case its time logs cannot change. The timesheet
+// row is locked, so in a transaction the week cannot be submitted before the
+// change is saved.
+func weekLocked(q sqlx.Queryer, employeeID, date string) (bool, error) {
+	var locked bool
+	query := `SELECT COALESCE(bool_or(status IN ('submitted', 'approved')), false)
+	          FROM (SELECT status FROM timesheets
+	                WHERE employee_id = $1 AND week_start = date_trunc('week', $2::date)::date
+	                FOR SHARE) t`
+	err := sqlx.Get(q, &locked, query, employeeID, date)
+	return locked, err
+}
+
+// timeLogLocked is weekLocked for the week a saved time log falls in.
+func timeLogLocked(q sqlx.Queryer, timeLogID string) (bool, error) {
+	var locked bool
+	query := `SELECT COALESCE(bool_or(status IN ('submitted', 'approved')), false)
+	          FROM (SELECT t.status FROM time_logs l
+	                JOIN timesheets t ON t.employee_id = l.employee_id AND t.week_start = date_trunc('week', l.log_date)::date
+	                WHERE l.id = $1
+	                FOR SHARE OF t) t`
+	err := sqlx.Get(q, &locked, query, timeLogID)
+	return locked, err
+}
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/aalsa/management_dashboard/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+type UserHandler struct {
+	db *sqlx.DB
+}
+
+func NewUserHandler(db *sqlx.DB) *UserHandler {
+	return &UserHandler{db: db}
+}
+
+type UpdateRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=admin manager member viewer"`
+}
+
+type LinkEmployeeRequest struct {
+	EmployeeID *string `json:"employee_id"`
+}
+
+func (h *UserHandler) GetAll(c *gin.Context) {
+	var users []models.User
+	query := `SELECT * FROM users ORDER BY email`
+
+	if err := h.db.Select(&users, query); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, users)
+}
+
+func (h *UserHandler) UpdateRole(c *gin.Context) {
+	id := c.Param("id")
+	var req UpdateRoleRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if id == c.GetString("userID") && req.Role != models.RoleAdmin {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Admins cannot demote themselves"})
+		return
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer tx.Rollback()
+
+	var role string
+	if err := tx.Get(&role, `SELECT role FROM users WHERE id = $1 FOR UPDATE`, id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	if _, err := tx.Exec(`UPDATE users SET role = $1 WHERE id = $2`, req.Role, id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if role != req.Role {
+		if err := revokeSessions(tx, id); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User role updated"})
+}
+
+func (h *UserHandler) LinkEmployee(c *gin.Context) {
+	id := c.Param("id")
+	var req LinkEmployeeRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.EmployeeID != nil {
+		var exists bool
+		query := `SELECT EXISTS (SELECT 1 FROM employees WHERE id = $1)`
+		if err := h.db.Get(&exists, query, *req.EmployeeID); err != nil || !exists {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Employee not found"})
+			return
+		}
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer tx.Rollback()
+
+	var employeeID *string
+	if err := tx.Get(&employeeID, `SELECT employee_id FROM users WHERE id = $1 FOR UPDATE`, id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	_, err = tx.Exec(`UPDATE users SET employee_id = $1 WHERE id = $2`, req.EmployeeID, id)
+	if isUniqueViolation(err) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Employee is already linked to another account"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !sameValue(employeeID, req.EmployeeID) {
+		if err := revokeSessions(tx, id); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User employee link updated"})
+}
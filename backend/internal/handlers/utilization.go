@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// utilizationGroups group active employees, aliased as e, with their
+// department as d.
+var utilizationGroups = map[string]analyticsGroup{
+	"employee":   {id: "e.id::text", name: "e.full_name"},
+	"department": {id: "e.department_id::text", name: "d.name"},
+	"team":       {id: "tt.id::text", name: "tt.name", join: teamJoins},
+}
+
+type UtilizationWeek struct {
+	Week           time.Time `db:"week" json:"week"`
+	GroupID        *string   `db:"group_id" json:"group_id"`
+	GroupName      *string   `db:"group_name" json:"group_name"`
+	Headcount      int       `db:"headcount" json:"headcount"`
+	CapacityHours  float64   `db:"capacity_hours" json:"capacity_hours"`
+	LeaveHours     float64   `db:"leave_hours" json:"leave_hours"`
+	LoggedHours    float64   `db:"logged_hours" json:"logged_hours"`
+	PlannedHours   float64   `db:"planned_hours" json:"planned_hours"`
+	Utilization    *float64  `db:"utilization" json:"utilization"`
+	OverAllocated  bool      `db:"over_allocated" json:"over_allocated"`
+	UnderAllocated bool      `db:"under_allocated" json:"under_allocated"`
+}
+
+// GetUtilization compares each active employee's weekly capacity with the
+// hours they logged that week plus the remaining estimate of their open
+// tasks. Approved leave and holidays take a fifth of the weekly capacity per
+// weekday off, reported as leave_hours. Open work counts in the week it is
+// due, or in the current week when it is undated or overdue. Weeks above 100%
+// are over-allocated and weeks below ?floor percent (default 50)
+// under-allocated. ?group_by=department or team sums employees per department
+// or team, and ?department_id= and ?team_id= limit the employees included.
+func (h *AnalyticsHandler) GetUtilization(c *gin.Context) {
+	q, err := parseAnalyticsQuery(c, utilizationGroups, "employee")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	floor := 50.0
+	if value := c.Query("floor"); value != "" {
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil || f < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "floor must be a non-negative percentage"})
+			return
+		}
+		floor = f
+	}
+
+	args := []interface{}{q.args[0], q.args[1], floor}
+	filter := ""
+	for _, f := range []struct{ param, condition string }{
+		{"department_id", "e.department_id = $%d"},
+		{"team_id", "e.id IN (SELECT employee_id FROM team_members WHERE team_id = $%d)"},
+	} {
+		value := c.Query(f.param)
+		if value == "" {
+			continue
+		}
+		if _, err := uuid.Parse(value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": f.param + " must be a valid id"})
+			return
+		}
+		args = append(args, value)
+		filter += " AND " + fmt.Sprintf(f.condition, len(args))
+	}
+
+	weeks := []UtilizationWeek{}
+	query := fmt.Sprintf(`
+	WITH weeks AS (
+		SELECT generate_series(date_trunc('week', $1::timestamp), $2::timestamp - interval '1 day', interval '1 week')::date AS week
+	),
+	logged AS (
+		SELECT employee_id, date_trunc('week', log_date)::date AS week, SUM(hours) AS hours
+		FROM time_logs
+		WHERE log_date >= date_trunc('week', $1::timestamp) AND log_date < $2::date
+		GROUP BY 1, 2
+	),
+	off AS (
+		SELECT employee_id, date_trunc('week', day)::date AS week, LEAST(COUNT(*), 5) AS days
+		FROM %s
+		WHERE day >= date_trunc('week', $1::timestamp) AND day < $2::date
+		GROUP BY 1, 2
+	),
+	planned AS (
+		SELECT t.assigned_to AS employee_id,
+		       date_trunc('week', GREATEST(COALESCE(t.due_date, CURRENT_DATE), CURRENT_DATE))::date AS week,
+		       SUM(GREATEST(t.estimated_hours - COALESCE((SELECT SUM(l.hours) FROM time_logs l WHERE l.task_id = t.id), 0), 0)) AS hours
+		FROM tasks t
+		WHERE t.completed_at IS NULL AND t.estimated_hours IS NOT NULL AND t.assigned_to IS NOT NULL
+		GROUP BY 1, 2
+	),
+	totals AS (
+		SELECT w.week, %s AS group_id, %s AS group_name, COUNT(*) AS headcount,
+		       SUM(e.weekly_capacity_hours * (1 - COALESCE(o.days, 0) / 5.0)) AS capacity_hours,
+		       SUM(e.weekly_capacity_hours * COALESCE(o.days, 0) / 5.0) AS leave_hours,
+		       COALESCE(SUM(l.hours), 0) AS logged_hours,
+		       COALESCE(SUM(pl.hours), 0) AS planned_hours
+		FROM weeks w
+		CROSS JOIN employees e
+		JOIN departments d ON d.id = e.department_id%s
+		LEFT JOIN logged l ON l.employee_id = e.id AND l.week = w.week
+		LEFT JOIN off o ON o.employee_id = e.id AND o.week = w.week
+		LEFT JOIN planned pl ON pl.employee_id = e.id AND pl.week = w.week
+		WHERE e.status = 'active'%s
+		GROUP BY 1, 2, 3
+	)
+	SELECT week, group_id, group_name, headcount, capacity_hours, leave_hours, logged_hours, planned_hours, utilization,
+	       COALESCE(utilization > 100, false) AS over_allocated,
+	       COALESCE(utilization < $3, false) AS under_allocated
+	FROM (
+		SELECT *, ROUND(100 * (logged_hours + planned_hours) / NULLIF(capacity_hours, 0), 1) AS utilization
+		FROM totals
+	) weekly
+	ORDER BY week, group_name`, daysOffTable, q.group.id, q.group.name, q.group.join, filter)
+	if err := h.db.Select(&weeks, query, args...); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	respondReport(c, q.response(weeks, nil), csvSection{"weeks", weeks})
+}
@@ -0,0 +1,360 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/aalsa/management_dashboard/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// completedState is the state every workflow must have. Tasks in it count
+// as done: they get a completed_at and no longer block other tasks.
+const completedState = "completed"
+
+// Projects without a custom workflow allow any move between these states.
+var defaultWorkflowStates = []string{"todo", "in_progress", "in_review", "blocked", completedState}
+
+// defaultParkingStates are the states of the default workflow that tasks
+// with open blockers may move to.
+var defaultParkingStates = []string{"blocked"}
+
+var stateNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,19}$`)
+
+type WorkflowHandler struct {
+	db *sqlx.DB
+}
+
+func NewWorkflowHandler(db *sqlx.DB) *WorkflowHandler {
+	return &WorkflowHandler{db: db}
+}
+
+type workflowTransitionRow struct {
+	FromState string         `db:"from_state"`
+	ToState   string         `db:"to_state"`
+	Roles     pq.StringArray `db:"roles"`
+}
+
+func (h *WorkflowHandler) Get(c *gin.Context) {
+	id := c.Param("id")
+
+	var exists bool
+	if err := h.db.Get(&exists, `SELECT EXISTS (SELECT 1 FROM projects WHERE id = $1)`, id); err != nil || !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		return
+	}
+
+	workflow, err := loadWorkflow(h.db, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, workflow)
+}
+
+func (h *WorkflowHandler) Update(c *gin.Context) {
+	id := c.Param("id")
+	var workflow models.Workflow
+
+	if err := c.ShouldBindJSON(&workflow); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if problem := validateWorkflow(workflow); problem != "" {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": problem})
+		return
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer tx.Rollback()
+
+	if found, err := lockWorkflow(tx, id); err != nil || !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		return
+	}
+
+	if problem, err := orphanedStatuses(tx, id, workflow.States); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	} else if problem != "" {
+		c.JSON(http.StatusConflict, gin.H{"error": problem})
+		return
+	}
+
+	if _, err := tx.Exec(`DELETE FROM workflow_states WHERE project_id = $1`, id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	parking := make(map[string]bool)
+	for _, state := range workflow.ParkingStates {
+		parking[state] = true
+	}
+	for i, state := range workflow.States {
+		query := `INSERT INTO workflow_states (project_id, name, position, parking) VALUES ($1, $2, $3, $4)`
+		if _, err := tx.Exec(query, id, state, i, parking[state]); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	for _, t := range workflow.Transitions {
+		if t.Roles == nil {
+			t.Roles = []string{}
+		}
+		query := `INSERT INTO workflow_transitions (project_id, from_state, to_state, roles) VALUES ($1, $2, $3, $4)`
+		if _, err := tx.Exec(query, id, t.From, t.To, pq.Array(t.Roles)); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	workflow, err = loadWorkflow(h.db, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, workflow)
+}
+
+// Reset drops the project's custom workflow so the default applies again.
+func (h *WorkflowHandler) Reset(c *gin.Context) {
+	id := c.Param("id")
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer tx.Rollback()
+
+	if found, err := lockWorkflow(tx, id); err != nil || !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		return
+	}
+
+	if problem, err := orphanedStatuses(tx, id, defaultWorkflowStates); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	} else if problem != "" {
+		c.JSON(http.StatusConflict, gin.H{"error": problem})
+		return
+	}
+
+	if _, err := tx.Exec(`DELETE FROM workflow_states WHERE project_id = $1`, id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Workflow reset to default"})
+}
+
+func loadWorkflow(db sqlx.Queryer, projectID string) (models.Workflow, error) {
+	workflow := models.Workflow{ProjectID: projectID, ParkingStates: []string{}, Transitions: []models.WorkflowTransition{}}
+
+	var states []struct {
+		Name    string `db:"name"`
+		Parking bool   `db:"parking"`
+	}
+	query := `SELECT name, parking FROM workflow_states WHERE project_id = $1 ORDER BY position`
+	if err := sqlx.Select(db, &states, query, projectID); err != nil {
+		return workflow, err
+	}
+
+	if len(states) == 0 {
+		workflow.States = defaultWorkflowStates
+		workflow.ParkingStates = defaultParkingStates
+		for _, from := range defaultWorkflowStates {
+			for _, to := range defaultWorkflowStates {
+				if from != to {
+					workflow.Transitions = append(workflow.Transitions,
+						models.WorkflowTransition{From: from, To: to, Roles: []string{}})
+				}
+			}
+		}
+		return workflow, nil
+	}
+
+	workflow.Custom = true
+	for _, state := range states {
+		workflow.States = append(workflow.States, state.Name)
+		if state.Parking {
+			workflow.ParkingStates = append(workflow.ParkingStates, state.Name)
+		}
+	}
+
+	var rows []workflowTransitionRow
+	transitionsQuery := `SELECT t.from_state, t.to_state, t.roles
+	                     FROM workflow_transitions t
+	                     JOIN workflow_states f ON f.project_id = t.project_id AND f.name = t.from_state
+	                     JOIN workflow_states s ON s.project_id = t.project_id AND s.name = t.to_state
+	                     WHERE t.project_id = $1
+	                     ORDER BY f.position, s.position`
+	if err := sqlx.Select(db, &rows, transitionsQuery, projectID); err != nil {
+		return workflow, err
+	}
+
+	for _, row := range rows {
+		workflow.Transitions = append(workflow.Transitions,
+			models.WorkflowTransition{From: row.FromState, To: row.ToState, Roles: row.Roles})
+	}
+	return workflow, nil
+}
+
+func validateWorkflow(workflow models.Workflow) string {
+	states := make(map[string]bool)
+	for _, state := range workflow.States {
+		if !stateNamePattern.MatchString(state) {
+			return fmt.Sprintf("Invalid state name %q: use lowercase letters, digits and underscores (max 20)", state)
+		}
+		if states[state] {
+			return fmt.Sprintf("State %q is listed more than once", state)
+		}
+		states[state] = true
+	}
+
+	// Completion drives completed_at, blockers and reporting, so every workflow needs it.
+	if !states[completedState] {
+		return fmt.Sprintf("Workflow must include the %q state", completedState)
+	}
+
+	for _, state := range workflow.ParkingStates {
+		if !states[state] {
+			return fmt.Sprintf("Parking state %q is not in the workflow", state)
+		}
+	}
+
+	validRoles := map[string]bool{
+		models.RoleAdmin: true, models.RoleManager: true, models.RoleMember: true, models.RoleViewer: true,
+	}
+	seen := make(map[string]bool)
+	for _, t := range workflow.Transitions {
+		if !states[t.From] || !states[t.To] {
+			return fmt.Sprintf("Transition %s -> %s uses a state that is not in the workflow", t.From, t.To)
+		}
+		if t.From == t.To {
+			return fmt.Sprintf("Transition %s -> %s must change state", t.From, t.To)
+		}
+		if seen[t.From+"->"+t.To] {
+			return fmt.Sprintf("Transition %s -> %s is listed more than once", t.From, t.To)
+		}
+		seen[t.From+"->"+t.To] = true
+		for _, role := range t.Roles {
+			if !validRoles[role] {
+				return fmt.Sprintf("Unknown role %q on transition %s -> %s", role, t.From, t.To)
+			}
+		}
+	}
+
+	return ""
+}
+
+// lockWorkflow locks the project row until tx ends. Task saves share that
+// lock in checkTaskStatus, so no task can move into a state the workflow is
+// dropping while it changes. It reports false when the project does not exist.
+func lockWorkflow(tx *sqlx.Tx, projectID string) (bool, error) {
+	var ids []string
+	err := tx.Select(&ids, `SELECT id FROM projects WHERE id = $1 FOR UPDATE`, projectID)
+	return len(ids) > 0, err
+}
+
+func orphanedStatuses(q sqlx.Queryer, projectID string, states []string) (string, error) {
+	var orphaned []string
+	query := `SELECT DISTINCT status FROM tasks WHERE project_id = $1 AND status <> ALL($2) ORDER BY status`
+	if err := sqlx.Select(q, &orphaned, query, projectID, pq.Array(states)); err != nil {
+		return "", err
+	}
+	if len(orphaned) > 0 {
+		return fmt.Sprintf("Tasks in this project use states missing from the workflow: %s", strings.Join(orphaned, ", ")), nil
+	}
+	return "", nil
+}
+
+// checkTaskStatus validates a task's new status against its project's
+// workflow. It returns the HTTP status and message for an illegal move, or 0
+// when the move is allowed. from is empty for new tasks.
+func checkTaskStatus(db sqlx.Queryer, projectID, from, to, role string) (int, string, error) {
+	// Holds off workflow changes until the task is saved; see lockWorkflow.
+	var ids []string
+	if err := sqlx.Select(db, &ids, `SELECT id FROM projects WHERE id = $1 FOR SHARE`, projectID); err != nil {
+		return 0, "", err
+	}
+
+	workflow, err := loadWorkflow(db, projectID)
+	if err != nil {
+		return 0, "", err
+	}
+
+	status, message := checkMove(workflow, from, to, role)
+	return status, message, nil
+}
+
+// blockedMoveAllowed reports whether a task with open blockers may move to
+// state: back to the workflow's first state or to one of its parking states.
+func blockedMoveAllowed(workflow models.Workflow, state string) bool {
+	if state == workflow.States[0] {
+		return true
+	}
+	for _, parking := range workflow.ParkingStates {
+		if state == parking {
+			return true
+		}
+	}
+	return false
+}
+
+// checkMove applies the workflow's states and transitions to a status change
+// by role, as checkTaskStatus describes.
+func checkMove(workflow models.Workflow, from, to, role string) (int, string) {
+	known := false
+	for _, state := range workflow.States {
+		known = known || state == to
+	}
+	if !known {
+		return http.StatusUnprocessableEntity, fmt.Sprintf("Unknown status %q; allowed states are %s",
+			to, strings.Join(workflow.States, ", "))
+	}
+
+	if from == "" || from == to {
+		return 0, ""
+	}
+
+	for _, t := range workflow.Transitions {
+		if t.From != from || t.To != to {
+			continue
+		}
+		if len(t.Roles) == 0 {
+			return 0, ""
+		}
+		for _, allowed := range t.Roles {
+			if allowed == role {
+				return 0, ""
+			}
+		}
+		return http.StatusForbidden, fmt.Sprintf("Only %s can move tasks from %s to %s",
+			strings.Join(t.Roles, ", "), from, to)
+	}
+
+	return http.StatusUnprocessableEntity, fmt.Sprintf("Moving a task from %s to %s is not allowed by this project's workflow", from, to)
+}
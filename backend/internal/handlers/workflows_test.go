@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"net/http"
+	"testing"
+
+	"github.com/aalsa/management_dashboard/internal/models"
+)
+
+const testProjectID = "5f0c6a52-3f1e-4d2b-9a57-1f3c2b9d8e41"
+
+func TestValidateWorkflow(t *testing.T) {
+	move := func(from, to string, roles ...string) models.WorkflowTransition {
+		return models.WorkflowTransition{From: from, To: to, Roles: roles}
+	}
+
+	tests := []struct {
+		name        string
+		states      []string
+		transitions []models.WorkflowTransition
+		wantOK      bool
+	}{
+		{"valid", []string{"todo", "doing", "completed"},
+			[]models.WorkflowTransition{move("todo", "doing"), move("doing", "completed", "manager")}, true},
+		{"no transitions", []string{"completed"}, nil, true},
+		{"missing completed", []string{"todo", "done"}, nil, false},
+		{"uppercase state", []string{"Todo", "completed"}, nil, false},
+		{"state with spaces", []string{"in review", "completed"}, nil, false},
+		{"state too long", []string{"waiting_for_customer_reply", "completed"}, nil, false},
+		{"duplicate state", []string{"todo", "todo", "completed"}, nil, false},
+		{"unknown state in transition", []string{"todo", "completed"},
+			[]models.WorkflowTransition{move("todo", "archived")}, false},
+		{"transition to itself", []string{"todo", "completed"},
+			[]models.WorkflowTransition{move("todo", "todo")}, false},
+		{"duplicate transition", []string{"todo", "completed"},
+			[]models.WorkflowTransition{move("todo", "completed"), move("todo", "completed")}, false},
+		{"unknown role", []string{"todo", "completed"},
+			[]models.WorkflowTransition{move("todo", "completed", "owner")}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problem := validateWorkflow(models.Workflow{States: tt.states, Transitions: tt.transitions})
+			if (problem == "") != tt.wantOK {
+				t.Errorf("validateWorkflow() = %q, want ok %v", problem, tt.wantOK)
+			}
+		})
+	}
+
+	parked := models.Workflow{States: []string{"todo", "on_hold", "completed"}, ParkingStates: []string{"on_hold"}}
+	if problem := validateWorkflow(parked); problem != "" {
+		t.Errorf("validateWorkflow() with a parking state = %q, want ok", problem)
+	}
+	parked.ParkingStates = []string{"blocked"}
+	if problem := validateWorkflow(parked); problem == "" {
+		t.Errorf("validateWorkflow() with an unknown parking state is ok, want a problem")
+	}
+}
+
+func TestBlockedTaskMoves(t *testing.T) {
+	states := fakeStep{match: "FROM workflow_states", columns: []string{"name", "parking"}, rows: [][]driver.Value{
+		{"todo", false}, {"in_progress", false}, {"on_hold", true}, {"blocked", false}, {"completed", false},
+	}}
+	transitions := fakeStep{match: "FROM workflow_transitions", columns: []string{"from_state", "to_state", "roles"}}
+	for _, to := range []string{"todo", "on_hold", "blocked", "completed"} {
+		transitions.rows = append(transitions.rows, []driver.Value{"in_progress", to, []byte("{}")})
+	}
+
+	tests := []struct {
+		name         string
+		to           string
+		openBlockers int64
+		wantStatus   int
+	}{
+		{"back to the first state", "todo", 1, 0},
+		{"to a parking state", "on_hold", 1, 0},
+		{"blocked is not a parking state here", "blocked", 1, http.StatusConflict},
+		{"on with open blockers", "completed", 2, http.StatusConflict},
+		{"on once blockers are done", "completed", 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			steps := []fakeStep{
+				{match: "FOR SHARE", columns: []string{"id"}, rows: [][]driver.Value{{"p1"}}},
+				states, transitions, states, transitions,
+			}
+			if tt.to != "todo" && tt.to != "on_hold" {
+				steps = append(steps, fakeStep{match: "FROM task_dependencies", columns: []string{"count"},
+					rows: [][]driver.Value{{tt.openBlockers}}})
+			}
+			db, f := newFakeDB(t, steps...)
+			tx, err := db.Beginx()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer tx.Rollback()
+
+			existing := models.Task{ID: "t1", Title: "Ship", ProjectID: testProjectID, Status: "in_progress", Priority: "medium"}
+			task := existing
+			task.Status = tt.to
+			c := testContext("")
+			c.Set("role", models.RoleAdmin)
+
+			status, message, err := checkTask(c, tx, &existing, task)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if status != tt.wantStatus {
+				t.Errorf("checkTask() to %s = %d %q, want %d", tt.to, status, message, tt.wantStatus)
+			}
+			if len(f.args) == 6 && f.args[5][1] != completedState {
+				t.Errorf("blockers counted against %v, want %q", f.args[5][1], completedState)
+			}
+		})
+	}
+}
+
+func TestCheckMove(t *testing.T) {
+	workflow := models.Workflow{
+		States: []string{"todo", "in_progress", "completed"},
+		Transitions: []models.WorkflowTransition{
+			{From: "todo", To: "in_progress", Roles: []string{}},
+			{From: "in_progress", To: "completed", Roles: []string{models.RoleAdmin, models.RoleManager}},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		from, to   string
+		role       string
+		wantStatus int
+	}{
+		{"new task in a known state", "", "todo", models.RoleMember, 0},
+		{"new task in an unknown state", "", "archived", models.RoleMember, http.StatusUnprocessableEntity},
+		{"unchanged", "in_progress", "in_progress", models.RoleMember, 0},
+		{"open transition", "todo", "in_progress", models.RoleMember, 0},
+		{"role allowed", "in_progress", "completed", models.RoleManager, 0},
+		{"role not allowed", "in_progress", "completed", models.RoleMember, http.StatusForbidden},
+		{"no such transition", "todo", "completed", models.RoleAdmin, http.StatusUnprocessableEntity},
+		{"move into an unknown state", "todo", "archived", models.RoleAdmin, http.StatusUnprocessableEntity},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, message := checkMove(workflow, tt.from, tt.to, tt.role)
+			if status != tt.wantStatus {
+				t.Errorf("checkMove(%q, %q, %q) = %d %q, want %d", tt.from, tt.to, tt.role, status, message, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -7,9 +7,10 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/jmoiron/sqlx"
 )
 
-func AuthMiddleware() gin.HandlerFunc {
+func AuthMiddleware(db *sqlx.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -51,8 +52,44 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		userID := claims["user_id"].(string)
+		userID, _ := claims["user_id"].(string)
+		role, _ := claims["role"].(string)
+		employeeID, _ := claims["employee_id"].(string)
+		sessionID, _ := claims["session_id"].(string)
+		if userID == "" || role == "" || sessionID == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
+			c.Abort()
+			return
+		}
+
+		var active bool
+		query := `SELECT EXISTS (SELECT 1 FROM sessions
+		          WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL AND expires_at > CURRENT_TIMESTAMP)`
+		if err := db.Get(&active, query, sessionID, userID); err != nil || !active {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Session has been revoked"})
+			c.Abort()
+			return
+		}
+
 		c.Set("userID", userID)
+		c.Set("sessionID", sessionID)
+		c.Set("role", role)
+		c.Set("employeeID", employeeID)
 		c.Next()
 	}
 }
+
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := c.GetString("role")
+		for _, allowed := range roles {
+			if role == allowed {
+				c.Next()
+				return
+			}
+		}
+
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+		c.Abort()
+	}
+}
@@ -2,16 +2,86 @@ package models
 
 import "time"
 
+const (
+	RoleAdmin   = "admin"
+	RoleManager = "manager"
+	RoleMember  = "member"
+	RoleViewer  = "viewer"
+)
+
 type Employee struct {
-	ID         string    `db:"id" json:"id"`
-	Email      string    `db:"email" json:"email"`
+	ID                  string    `db:"id" json:"id"`
+	Email               string    `db:"email" json:"email"`
+	FullName            string    `db:"full_name" json:"full_name"`
+	Role                string    `db:"role" json:"role"`
+	DepartmentID        string    `db:"department_id" json:"department_id"`
+	Department          string    `db:"department" json:"department"`
+	HireDate            string    `db:"hire_date" json:"hire_date"`
+	Status              string    `db:"status" json:"status"`
+	WeeklyCapacityHours *float64  `db:"weekly_capacity_hours" json:"weekly_capacity_hours"`
+	ManagerID           *string   `db:"manager_id" json:"manager_id"`
+	CreatedAt           time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt           time.Time `db:"updated_at" json:"updated_at"`
+}
+
+type Department struct {
+	ID                string    `db:"id" json:"id"`
+	Name              string    `db:"name" json:"name"`
+	HeadID            *string   `db:"head_id" json:"head_id"`
+	HolidayCalendarID *string   `db:"holiday_calendar_id" json:"holiday_calendar_id"`
+	Employees         int       `db:"employees" json:"employees"`
+	CreatedAt         time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt         time.Time `db:"updated_at" json:"updated_at"`
+}
+
+type Team struct {
+	ID           string    `db:"id" json:"id"`
+	Name         string    `db:"name" json:"name"`
+	DepartmentID *string   `db:"department_id" json:"department_id"`
+	LeadID       *string   `db:"lead_id" json:"lead_id"`
+	Members      int       `db:"members" json:"members"`
+	CreatedAt    time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt    time.Time `db:"updated_at" json:"updated_at"`
+}
+
+type TeamMember struct {
+	EmployeeID string    `db:"employee_id" json:"employee_id"`
 	FullName   string    `db:"full_name" json:"full_name"`
-	Role       string    `db:"role" json:"role"`
-	Department string    `db:"department" json:"department"`
-	HireDate   string    `db:"hire_date" json:"hire_date"`
-	Status     string    `db:"status" json:"status"`
-	CreatedAt  time.Time `db:"created_at" json:"created_at"`
-	UpdatedAt  time.Time `db:"updated_at" json:"updated_at"`
+	Email      string    `db:"email" json:"email"`
+	JoinedAt   time.Time `db:"created_at" json:"joined_at"`
+}
+
+type Skill struct {
+	ID        string    `db:"id" json:"id"`
+	Name      string    `db:"name" json:"name"`
+	Category  string    `db:"category" json:"category"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+type EmployeeSkill struct {
+	EmployeeID  string    `db:"employee_id" json:"employee_id"`
+	SkillID     string    `db:"skill_id" json:"skill_id"`
+	Name        string    `db:"name" json:"name"`
+	Category    string    `db:"category" json:"category"`
+	Proficiency int       `db:"proficiency" json:"proficiency"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+}
+
+type TaskSkill struct {
+	TaskID         string `db:"task_id" json:"task_id"`
+	SkillID        string `db:"skill_id" json:"skill_id"`
+	Name           string `db:"name" json:"name"`
+	Category       string `db:"category" json:"category"`
+	MinProficiency int    `db:"min_proficiency" json:"min_proficiency"`
+}
+
+type EmployeeRate struct {
+	ID            string    `db:"id" json:"id"`
+	EmployeeID    string    `db:"employee_id" json:"employee_id"`
+	HourlyRate    float64   `db:"hourly_rate" json:"hourly_rate"`
+	EffectiveFrom string    `db:"effective_from" json:"effective_from"`
+	CreatedAt     time.Time `db:"created_at" json:"created_at"`
 }
 
 type Project struct {
@@ -22,22 +92,26 @@ type Project struct {
 	EndDate     *string   `db:"end_date" json:"end_date"`
 	Status      string    `db:"status" json:"status"`
 	Budget      *float64  `db:"budget" json:"budget"`
+	ClientID    *string   `db:"client_id" json:"client_id"`
 	CreatedAt   time.Time `db:"created_at" json:"created_at"`
 	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
 }
 
 type Task struct {
-	ID          string     `db:"id" json:"id"`
-	Title       string     `db:"title" json:"title"`
-	Description string     `db:"description" json:"description"`
-	ProjectID   string     `db:"project_id" json:"project_id"`
-	AssignedTo  *string    `db:"assigned_to" json:"assigned_to"`
-	Status      string     `db:"status" json:"status"`
-	Priority    string     `db:"priority" json:"priority"`
-	DueDate     *string    `db:"due_date" json:"due_date"`
-	CreatedAt   time.Time  `db:"created_at" json:"created_at"`
-	UpdatedAt   time.Time  `db:"updated_at" json:"updated_at"`
-	CompletedAt *time.Time `db:"completed_at" json:"completed_at"`
+	ID             string     `db:"id" json:"id"`
+	Title          string     `db:"title" json:"title"`
+	Description    string     `db:"description" json:"description"`
+	ProjectID      string     `db:"project_id" json:"project_id"`
+	ParentID       *string    `db:"parent_id" json:"parent_id"`
+	AssignedTo     *string    `db:"assigned_to" json:"assigned_to"`
+	Status         string     `db:"status" json:"status"`
+	Priority       string     `db:"priority" json:"priority"`
+	DueDate        *string    `db:"due_date" json:"due_date"`
+	StoryPoints    *int       `db:"story_points" json:"story_points"`
+	EstimatedHours *float64   `db:"estimated_hours" json:"estimated_hours"`
+	CreatedAt      time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time  `db:"updated_at" json:"updated_at"`
+	CompletedAt    *time.Time `db:"completed_at" json:"completed_at"`
 }
 
 type TimeLog struct {
@@ -47,6 +121,8 @@ type TimeLog struct {
 	Hours      float64   `db:"hours" json:"hours"`
 	LogDate    string    `db:"log_date" json:"log_date"`
 	Notes      string    `db:"notes" json:"notes"`
+	Billable   bool      `db:"billable" json:"billable"`
+	InvoiceID  *string   `db:"invoice_id" json:"invoice_id"`
 	CreatedAt  time.Time `db:"created_at" json:"created_at"`
 }
 
@@ -55,6 +131,203 @@ type User struct {
 	EmployeeID   *string    `db:"employee_id" json:"employee_id"`
 	Email        string     `db:"email" json:"email"`
 	PasswordHash string     `db:"password_hash" json:"-"`
+	Role         string     `db:"role" json:"role"`
 	CreatedAt    time.Time  `db:"created_at" json:"created_at"`
 	LastLogin    *time.Time `db:"last_login" json:"last_login"`
 }
+
+type Session struct {
+	ID                string     `db:"id" json:"id"`
+	UserID            string     `db:"user_id" json:"user_id"`
+	RefreshTokenHash  string     `db:"refresh_token_hash" json:"-"`
+	PreviousTokenHash *string    `db:"previous_token_hash" json:"-"`
+	UserAgent         string     `db:"user_agent" json:"user_agent"`
+	IPAddress         string     `db:"ip_address" json:"ip_address"`
+	CreatedAt         time.Time  `db:"created_at" json:"created_at"`
+	LastUsedAt        time.Time  `db:"last_used_at" json:"last_used_at"`
+	ExpiresAt         time.Time  `db:"expires_at" json:"expires_at"`
+	RevokedAt         *time.Time `db:"revoked_at" json:"revoked_at"`
+}
+
+type ProjectAssignment struct {
+	ProjectID         string    `db:"project_id" json:"project_id"`
+	EmployeeID        string    `db:"employee_id" json:"employee_id"`
+	AllocationPercent int       `db:"allocation_percent" json:"allocation_percent"`
+	ProjectRole       string    `db:"project_role" json:"project_role"`
+	AssignedAt        time.Time `db:"assigned_at" json:"assigned_at"`
+}
+
+type ProjectMember struct {
+	ProjectAssignment
+	FullName string `db:"full_name" json:"full_name"`
+	Email    string `db:"email" json:"email"`
+}
+
+type EmployeeProject struct {
+	ProjectAssignment
+	Name   string `db:"name" json:"name"`
+	Status string `db:"status" json:"status"`
+}
+
+type Timer struct {
+	ID         string    `db:"id" json:"id"`
+	EmployeeID string    `db:"employee_id" json:"employee_id"`
+	TaskID     string    `db:"task_id" json:"task_id"`
+	Notes      string    `db:"notes" json:"notes"`
+	Billable   bool      `db:"billable" json:"billable"`
+	StartedAt  time.Time `db:"started_at" json:"started_at"`
+}
+
+type Timesheet struct {
+	ID            string     `db:"id" json:"id"`
+	EmployeeID    string     `db:"employee_id" json:"employee_id"`
+	WeekStart     string     `db:"week_start" json:"week_start"`
+	Status        string     `db:"status" json:"status"`
+	SubmittedAt   *time.Time `db:"submitted_at" json:"submitted_at"`
+	ReviewedBy    *string    `db:"reviewed_by" json:"reviewed_by"`
+	ReviewedAt    *time.Time `db:"reviewed_at" json:"reviewed_at"`
+	ReviewComment string     `db:"review_comment" json:"review_comment"`
+	TotalHours    float64    `db:"total_hours" json:"total_hours"`
+	LeaveHours    float64    `db:"leave_hours" json:"leave_hours"`
+	ExpectedHours float64    `db:"expected_hours" json:"expected_hours"`
+	CreatedAt     time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt     time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+type HolidayCalendar struct {
+	ID        string    `db:"id" json:"id"`
+	Name      string    `db:"name" json:"name"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+type Holiday struct {
+	ID         string `db:"id" json:"id"`
+	CalendarID string `db:"calendar_id" json:"calendar_id"`
+	Date       string `db:"date" json:"date"`
+	Name       string `db:"name" json:"name"`
+}
+
+type LeaveRequest struct {
+	ID            string     `db:"id" json:"id"`
+	EmployeeID    string     `db:"employee_id" json:"employee_id"`
+	Type          string     `db:"type" json:"type"`
+	StartDate     string     `db:"start_date" json:"start_date"`
+	EndDate       string     `db:"end_date" json:"end_date"`
+	Days          float64    `db:"days" json:"days"`
+	Reason        string     `db:"reason" json:"reason"`
+	Status        string     `db:"status" json:"status"`
+	ReviewedBy    *string    `db:"reviewed_by" json:"reviewed_by"`
+	ReviewedAt    *time.Time `db:"reviewed_at" json:"reviewed_at"`
+	ReviewComment string     `db:"review_comment" json:"review_comment"`
+	CreatedAt     time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt     time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+type TaskDependency struct {
+	TaskID      string    `db:"task_id" json:"task_id"`
+	BlockedByID string    `db:"blocked_by_id" json:"blocked_by_id"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+}
+
+type TaskComment struct {
+	ID          string    `db:"id" json:"id"`
+	TaskID      string    `db:"task_id" json:"task_id"`
+	AuthorID    *string   `db:"author_id" json:"author_id"`
+	AuthorEmail *string   `db:"author_email" json:"author_email"`
+	Body        string    `db:"body" json:"body"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+}
+
+type Notification struct {
+	ID        string     `db:"id" json:"id"`
+	UserID    string     `db:"user_id" json:"user_id"`
+	Type      string     `db:"type" json:"type"`
+	TaskID    *string    `db:"task_id" json:"task_id"`
+	CommentID *string    `db:"comment_id" json:"comment_id"`
+	Message   string     `db:"message" json:"message"`
+	ReadAt    *time.Time `db:"read_at" json:"read_at"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+}
+
+type TaskEvent struct {
+	ID        string    `db:"id" json:"id"`
+	TaskID    string    `db:"task_id" json:"task_id"`
+	UserID    *string   `db:"user_id" json:"user_id"`
+	UserEmail *string   `db:"user_email" json:"user_email"`
+	Field     string    `db:"field" json:"field"`
+	OldValue  *string   `db:"old_value" json:"old_value"`
+	NewValue  *string   `db:"new_value" json:"new_value"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+type WorkflowTransition struct {
+	From  string   `json:"from" binding:"required"`
+	To    string   `json:"to" binding:"required"`
+	Roles []string `json:"roles"`
+}
+
+type Workflow struct {
+	ProjectID     string               `json:"project_id"`
+	Custom        bool                 `json:"custom"`
+	States        []string             `json:"states" binding:"required,min=1"`
+	ParkingStates []string             `json:"parking_states"`
+	Transitions   []WorkflowTransition `json:"transitions" binding:"dive"`
+}
+
+type Sprint struct {
+	ID        string     `db:"id" json:"id"`
+	ProjectID string     `db:"project_id" json:"project_id"`
+	Name      string     `db:"name" json:"name"`
+	Goal      string     `db:"goal" json:"goal"`
+	StartDate string     `db:"start_date" json:"start_date"`
+	EndDate   string     `db:"end_date" json:"end_date"`
+	Status    string     `db:"status" json:"status"`
+	StartedAt *time.Time `db:"started_at" json:"started_at"`
+	ClosedAt  *time.Time `db:"closed_at" json:"closed_at"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+type SprintTask struct {
+	Task
+	Committed   bool      `db:"committed" json:"committed"`
+	AddedAt     time.Time `db:"added_at" json:"added_at"`
+	CarriedOver bool      `db:"carried_over" json:"carried_over"`
+}
+
+type Client struct {
+	ID         string    `db:"id" json:"id"`
+	Name       string    `db:"name" json:"name"`
+	Email      *string   `db:"email" json:"email"`
+	Address    *string   `db:"address" json:"address"`
+	HourlyRate float64   `db:"hourly_rate" json:"hourly_rate"`
+	CreatedAt  time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt  time.Time `db:"updated_at" json:"updated_at"`
+}
+
+type Invoice struct {
+	ID          string     `db:"id" json:"id"`
+	Number      string     `db:"number" json:"number"`
+	ClientID    string     `db:"client_id" json:"client_id"`
+	PeriodStart string     `db:"period_start" json:"period_start"`
+	PeriodEnd   string     `db:"period_end" json:"period_end"`
+	Status      string     `db:"status" json:"status"`
+	Total       float64    `db:"total" json:"total"`
+	CreatedBy   *string    `db:"created_by" json:"created_by"`
+	IssuedAt    *time.Time `db:"issued_at" json:"issued_at"`
+	PaidAt      *time.Time `db:"paid_at" json:"paid_at"`
+	CreatedAt   time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+type InvoiceLineItem struct {
+	ID          string  `db:"id" json:"id"`
+	InvoiceID   string  `db:"invoice_id" json:"invoice_id"`
+	TaskID      *string `db:"task_id" json:"task_id"`
+	Description string  `db:"description" json:"description"`
+	Hours       float64 `db:"hours" json:"hours"`
+	Rate        float64 `db:"rate" json:"rate"`
+	Amount      float64 `db:"amount" json:"amount"`
+}
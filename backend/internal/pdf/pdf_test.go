@@ -0,0 +1,27 @@
+package pdf
+
+import "testing"
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want string
+	}{
+		{"ascii", "Invoice 42", "Invoice 42"},
+		{"parentheses", "Hours (billable)", `Hours \(billable\)`},
+		{"backslash", `C:\invoices`, `C:\\invoices`},
+		{"latin-1", "Café Müller", "Caf\xe9 M\xfcller"},
+		{"euro and dashes", "€10 – 12", "\x8010 \x96 12"},
+		{"curly quotes", "“done”", "\x93done\x94"},
+		{"unsupported", "日本", "??"},
+		{"control character", "a\tb", "a?b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := encode(tt.s); got != tt.want {
+				t.Errorf("encode(%q) = %q, want %q", tt.s, got, tt.want)
+			}
+		})
+	}
+}
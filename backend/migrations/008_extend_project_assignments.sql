@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE project_assignments
+    ADD COLUMN allocation_percent INTEGER NOT NULL DEFAULT 100 CHECK (allocation_percent BETWEEN 1 AND 100),
+    ADD COLUMN project_role VARCHAR(100) NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE project_assignments
+    DROP COLUMN IF EXISTS allocation_percent,
+    DROP COLUMN IF EXISTS project_role;
@@ -0,0 +1,24 @@
+-- +goose Up
+-- Emails are matched regardless of case, so one address can match several
+-- users or employees. An exact match wins, then the oldest record, so each
+-- user gets at most one employee and each employee at most one user.
+UPDATE users u SET employee_id = m.employee_id
+FROM (
+    SELECT DISTINCT ON (employee_id) user_id, employee_id
+    FROM (
+        SELECT DISTINCT ON (u.id) u.id AS user_id, e.id AS employee_id,
+               u.email = e.email AS exact, u.created_at AS user_created_at
+        FROM users u
+        JOIN employees e ON lower(u.email) = lower(e.email)
+        WHERE u.employee_id IS NULL
+        ORDER BY u.id, u.email = e.email DESC, e.created_at, e.id
+    ) per_user
+    ORDER BY employee_id, exact DESC, user_created_at, user_id
+) m
+WHERE u.id = m.user_id
+  AND NOT EXISTS (SELECT 1 FROM users linked WHERE linked.employee_id = m.employee_id);
+
+CREATE UNIQUE INDEX idx_users_employee ON users(employee_id) WHERE employee_id IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_users_employee;
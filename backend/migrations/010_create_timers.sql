@@ -0,0 +1,13 @@
+-- +goose Up
+CREATE TABLE timers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    employee_id UUID UNIQUE REFERENCES employees(id) ON DELETE CASCADE NOT NULL,
+    task_id UUID REFERENCES tasks(id) ON DELETE CASCADE NOT NULL,
+    notes TEXT NOT NULL DEFAULT '',
+    started_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_timers_started_at ON timers(started_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS timers;
@@ -0,0 +1,13 @@
+-- +goose Up
+CREATE TABLE task_dependencies (
+    task_id UUID REFERENCES tasks(id) ON DELETE CASCADE,
+    blocked_by_id UUID REFERENCES tasks(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (task_id, blocked_by_id),
+    CHECK (task_id <> blocked_by_id)
+);
+
+CREATE INDEX idx_task_dependencies_blocked_by ON task_dependencies(blocked_by_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS task_dependencies;
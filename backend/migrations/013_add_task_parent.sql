@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE tasks ADD COLUMN parent_id UUID REFERENCES tasks(id) ON DELETE CASCADE;
+
+CREATE INDEX idx_tasks_parent ON tasks(parent_id);
+
+-- +goose Down
+ALTER TABLE tasks DROP COLUMN IF EXISTS parent_id;
@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE task_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    task_id UUID REFERENCES tasks(id) ON DELETE CASCADE NOT NULL,
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    field VARCHAR(50) NOT NULL CHECK (field IN ('status', 'assigned_to', 'priority')),
+    old_value TEXT,
+    new_value TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_task_events_task ON task_events(task_id, created_at);
+
+UPDATE tasks SET completed_at = updated_at WHERE status = 'completed' AND completed_at IS NULL;
+
+-- +goose Down
+DROP TABLE IF EXISTS task_events;
@@ -0,0 +1,27 @@
+-- +goose Up
+ALTER TABLE tasks DROP CONSTRAINT IF EXISTS tasks_status_check;
+
+CREATE TABLE workflow_states (
+    project_id UUID REFERENCES projects(id) ON DELETE CASCADE,
+    name VARCHAR(20) NOT NULL,
+    position INTEGER NOT NULL,
+    PRIMARY KEY (project_id, name)
+);
+
+CREATE TABLE workflow_transitions (
+    project_id UUID NOT NULL,
+    from_state VARCHAR(20) NOT NULL,
+    to_state VARCHAR(20) NOT NULL,
+    roles TEXT[] NOT NULL DEFAULT '{}',
+    PRIMARY KEY (project_id, from_state, to_state),
+    FOREIGN KEY (project_id, from_state) REFERENCES workflow_states(project_id, name) ON DELETE CASCADE,
+    FOREIGN KEY (project_id, to_state) REFERENCES workflow_states(project_id, name) ON DELETE CASCADE,
+    CHECK (from_state <> to_state)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS workflow_transitions;
+DROP TABLE IF EXISTS workflow_states;
+
+UPDATE tasks SET status = 'todo' WHERE status NOT IN ('todo', 'in_progress', 'completed');
+ALTER TABLE tasks ADD CONSTRAINT tasks_status_check CHECK (status IN ('todo', 'in_progress', 'completed'));
@@ -0,0 +1,33 @@
+-- +goose Up
+CREATE TABLE sprints (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id UUID REFERENCES projects(id) ON DELETE CASCADE NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    goal TEXT NOT NULL DEFAULT '',
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'planned' CHECK (status IN ('planned', 'active', 'closed')),
+    started_at TIMESTAMP,
+    closed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (end_date >= start_date)
+);
+
+CREATE TABLE sprint_tasks (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    sprint_id UUID REFERENCES sprints(id) ON DELETE CASCADE NOT NULL,
+    task_id UUID REFERENCES tasks(id) ON DELETE CASCADE NOT NULL,
+    committed BOOLEAN NOT NULL,
+    added_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    removed_at TIMESTAMP,
+    carried_over BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+CREATE INDEX idx_sprints_project ON sprints(project_id);
+CREATE INDEX idx_sprint_tasks_task ON sprint_tasks(task_id);
+CREATE UNIQUE INDEX idx_sprint_tasks_current ON sprint_tasks(sprint_id, task_id) WHERE removed_at IS NULL;
+
+-- +goose Down
+DROP TABLE IF EXISTS sprint_tasks;
+DROP TABLE IF EXISTS sprints;
@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE tasks
+    ADD COLUMN story_points INTEGER CHECK (story_points >= 0),
+    ADD COLUMN estimated_hours DECIMAL(6, 2) CHECK (estimated_hours > 0);
+
+-- +goose Down
+ALTER TABLE tasks
+    DROP COLUMN IF EXISTS story_points,
+    DROP COLUMN IF EXISTS estimated_hours;
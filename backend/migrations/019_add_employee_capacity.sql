@@ -0,0 +1,6 @@
+-- +goose Up
+ALTER TABLE employees
+    ADD COLUMN weekly_capacity_hours DECIMAL(5, 2) NOT NULL DEFAULT 40 CHECK (weekly_capacity_hours >= 0 AND weekly_capacity_hours <= 168);
+
+-- +goose Down
+ALTER TABLE employees DROP COLUMN IF EXISTS weekly_capacity_hours;
@@ -0,0 +1,12 @@
+-- +goose Up
+CREATE TABLE employee_rates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    employee_id UUID REFERENCES employees(id) ON DELETE CASCADE NOT NULL,
+    hourly_rate DECIMAL(10, 2) NOT NULL CHECK (hourly_rate >= 0),
+    effective_from DATE NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (employee_id, effective_from)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS employee_rates;
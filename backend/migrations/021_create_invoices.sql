@@ -0,0 +1,61 @@
+-- +goose Up
+CREATE TABLE clients (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(255) UNIQUE NOT NULL,
+    email VARCHAR(255),
+    address TEXT,
+    hourly_rate DECIMAL(10, 2) NOT NULL DEFAULT 0 CHECK (hourly_rate >= 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE projects ADD COLUMN client_id UUID REFERENCES clients(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_projects_client ON projects(client_id);
+
+CREATE SEQUENCE invoice_number_seq;
+
+CREATE TABLE invoices (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    number VARCHAR(20) UNIQUE NOT NULL DEFAULT 'INV-' || lpad(nextval('invoice_number_seq')::text, 6, '0'),
+    client_id UUID REFERENCES clients(id) ON DELETE RESTRICT NOT NULL,
+    period_start DATE NOT NULL,
+    period_end DATE NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'draft' CHECK (status IN ('draft', 'issued', 'paid', 'void')),
+    total DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    issued_at TIMESTAMP,
+    paid_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (period_end >= period_start)
+);
+
+CREATE TABLE invoice_line_items (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    invoice_id UUID REFERENCES invoices(id) ON DELETE CASCADE NOT NULL,
+    task_id UUID REFERENCES tasks(id) ON DELETE SET NULL,
+    description TEXT NOT NULL,
+    hours DECIMAL(8, 2) NOT NULL,
+    rate DECIMAL(10, 2) NOT NULL,
+    amount DECIMAL(12, 2) NOT NULL
+);
+
+CREATE INDEX idx_invoices_client ON invoices(client_id);
+CREATE INDEX idx_invoice_line_items_invoice ON invoice_line_items(invoice_id);
+
+ALTER TABLE time_logs
+    ADD COLUMN billable BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN invoice_id UUID REFERENCES invoices(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_time_logs_invoice ON time_logs(invoice_id);
+
+-- +goose Down
+ALTER TABLE time_logs
+    DROP COLUMN IF EXISTS invoice_id,
+    DROP COLUMN IF EXISTS billable;
+DROP TABLE IF EXISTS invoice_line_items;
+DROP TABLE IF EXISTS invoices;
+DROP SEQUENCE IF EXISTS invoice_number_seq;
+ALTER TABLE projects DROP COLUMN IF EXISTS client_id;
+DROP TABLE IF EXISTS clients;
@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE employees
+    ADD COLUMN manager_id UUID REFERENCES employees(id) ON DELETE SET NULL,
+    ADD CONSTRAINT employees_manager_not_self CHECK (manager_id <> id);
+
+CREATE INDEX idx_employees_manager ON employees(manager_id);
+
+-- +goose Down
+ALTER TABLE employees
+    DROP CONSTRAINT IF EXISTS employees_manager_not_self,
+    DROP COLUMN IF EXISTS manager_id;
@@ -0,0 +1,69 @@
+-- +goose Up
+CREATE TABLE departments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(100) NOT NULL,
+    head_id UUID REFERENCES employees(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_departments_name ON departments(lower(name));
+
+CREATE TABLE teams (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(100) NOT NULL,
+    department_id UUID REFERENCES departments(id) ON DELETE SET NULL,
+    lead_id UUID REFERENCES employees(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_teams_name ON teams(lower(name));
+CREATE INDEX idx_teams_department ON teams(department_id);
+
+CREATE TABLE team_members (
+    team_id UUID REFERENCES teams(id) ON DELETE CASCADE NOT NULL,
+    employee_id UUID REFERENCES employees(id) ON DELETE CASCADE NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (team_id, employee_id)
+);
+
+CREATE INDEX idx_team_members_employee ON team_members(employee_id);
+
+-- Spellings that differ only in case or surrounding spaces become one
+-- department, named after the most common spelling.
+INSERT INTO departments (name)
+SELECT DISTINCT ON (lower(name)) name
+FROM (
+    SELECT COALESCE(NULLIF(btrim(department), ''), 'Unassigned') AS name, COUNT(*) AS employees
+    FROM employees
+    GROUP BY 1
+) spellings
+ORDER BY lower(name), employees DESC, name;
+
+ALTER TABLE employees ADD COLUMN department_id UUID REFERENCES departments(id) ON DELETE RESTRICT;
+
+UPDATE employees e SET department_id = d.id
+FROM departments d
+WHERE lower(d.name) = lower(COALESCE(NULLIF(btrim(e.department), ''), 'Unassigned'));
+
+ALTER TABLE employees
+    ALTER COLUMN department_id SET NOT NULL,
+    DROP COLUMN department;
+
+CREATE INDEX idx_employees_department ON employees(department_id);
+
+-- +goose Down
+ALTER TABLE employees ADD COLUMN department VARCHAR(100);
+
+UPDATE employees e SET department = d.name
+FROM departments d
+WHERE d.id = e.department_id;
+
+ALTER TABLE employees
+    ALTER COLUMN department SET NOT NULL,
+    DROP COLUMN department_id;
+
+DROP TABLE IF EXISTS team_members;
+DROP TABLE IF EXISTS teams;
+DROP TABLE IF EXISTS departments;
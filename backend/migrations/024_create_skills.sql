@@ -0,0 +1,34 @@
+-- +goose Up
+CREATE TABLE skills (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(100) NOT NULL,
+    category VARCHAR(100) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_skills_name ON skills(lower(name));
+
+CREATE TABLE employee_skills (
+    employee_id UUID REFERENCES employees(id) ON DELETE CASCADE NOT NULL,
+    skill_id UUID REFERENCES skills(id) ON DELETE CASCADE NOT NULL,
+    proficiency SMALLINT NOT NULL CHECK (proficiency BETWEEN 1 AND 5),
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (employee_id, skill_id)
+);
+
+CREATE INDEX idx_employee_skills_skill ON employee_skills(skill_id);
+
+CREATE TABLE task_skills (
+    task_id UUID REFERENCES tasks(id) ON DELETE CASCADE NOT NULL,
+    skill_id UUID REFERENCES skills(id) ON DELETE CASCADE NOT NULL,
+    min_proficiency SMALLINT NOT NULL DEFAULT 1 CHECK (min_proficiency BETWEEN 1 AND 5),
+    PRIMARY KEY (task_id, skill_id)
+);
+
+CREATE INDEX idx_task_skills_skill ON task_skills(skill_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS task_skills;
+DROP TABLE IF EXISTS employee_skills;
+DROP TABLE IF EXISTS skills;
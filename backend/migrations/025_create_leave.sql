@@ -0,0 +1,56 @@
+-- +goose Up
+CREATE TABLE holiday_calendars (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_holiday_calendars_name ON holiday_calendars(lower(name));
+
+CREATE TABLE holidays (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    calendar_id UUID REFERENCES holiday_calendars(id) ON DELETE CASCADE NOT NULL,
+    date DATE NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    UNIQUE (calendar_id, date)
+);
+
+ALTER TABLE departments ADD COLUMN holiday_calendar_id UUID REFERENCES holiday_calendars(id) ON DELETE SET NULL;
+
+CREATE TABLE leave_requests (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    employee_id UUID REFERENCES employees(id) ON DELETE CASCADE NOT NULL,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('vacation', 'sick', 'other')),
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    days DECIMAL(5, 1) NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'approved', 'rejected', 'cancelled')),
+    reviewed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMP,
+    review_comment TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (end_date >= start_date)
+);
+
+CREATE INDEX idx_leave_requests_employee ON leave_requests(employee_id, start_date);
+CREATE INDEX idx_leave_requests_status ON leave_requests(status);
+
+CREATE TABLE leave_balances (
+    employee_id UUID REFERENCES employees(id) ON DELETE CASCADE NOT NULL,
+    year INTEGER NOT NULL,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('vacation', 'sick', 'other')),
+    allowance_days DECIMAL(5, 1) NOT NULL CHECK (allowance_days >= 0),
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (employee_id, year, type)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS leave_balances;
+DROP TABLE IF EXISTS leave_requests;
+ALTER TABLE departments DROP COLUMN IF EXISTS holiday_calendar_id;
+DROP TABLE IF EXISTS holidays;
+DROP TABLE IF EXISTS holiday_calendars;
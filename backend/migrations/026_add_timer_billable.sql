@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE timers ADD COLUMN billable BOOLEAN NOT NULL DEFAULT false;
+
+-- +goose Down
+ALTER TABLE timers DROP COLUMN IF EXISTS billable;
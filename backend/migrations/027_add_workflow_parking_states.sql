@@ -0,0 +1,8 @@
+-- +goose Up
+ALTER TABLE workflow_states ADD COLUMN parking BOOLEAN NOT NULL DEFAULT false;
+
+-- Custom workflows so far let tasks with open blockers move to blocked.
+UPDATE workflow_states SET parking = true WHERE name = 'blocked';
+
+-- +goose Down
+ALTER TABLE workflow_states DROP COLUMN IF EXISTS parking;
@@ -5,6 +5,84 @@ import './App.css';
 
 const API_URL = 'http://localhost:8080/api';
 
+// ============================================================================
+// API
+// ============================================================================
+
+// Access tokens expire after 15 minutes. apiFetch sends the stored token and,
+// on a 401, refreshes the session once and retries. Concurrent requests share
+// a single refresh: presenting a refresh token that was already rotated out
+// revokes the whole session.
+let refreshing: Promise<boolean> | null = null;
+let onSessionEnded = () => {};
+
+function storeSession(token: string, refreshToken: string) {
+  localStorage.setItem('token', token);
+  localStorage.setItem('refresh_token', refreshToken);
+}
+
+function clearSession() {
+  localStorage.removeItem('token');
+  localStorage.removeItem('refresh_token');
+}
+
+function refreshSession(): Promise<boolean> {
+  if (!refreshing) {
+    refreshing = (async () => {
+      const refreshToken = localStorage.getItem('refresh_token');
+      if (!refreshToken) return false;
+      try {
+        const response = await fetch(`${API_URL}/auth/refresh`, {
+          method: 'POST',
+          headers: { 'Content-Type': 'application/json' },
+          body: JSON.stringify({ refresh_token: refreshToken })
+        });
+        if (!response.ok) return false;
+        const data = await response.json();
+        storeSession(data.token, data.refresh_token);
+        return true;
+      } catch {
+        return false;
+      }
+    })().finally(() => {
+      refreshing = null;
+    });
+  }
+  return refreshing;
+}
+
+async function apiFetch(path: string, init: RequestInit = {}): Promise<Response> {
+  const send = () => fetch(`${API_URL}${path}`, {
+    ...init,
+    headers: { ...(init.headers as Record<string, string>), Authorization: `Bearer ${localStorage.getItem('token')}` }
+  });
+
+  const response = await send();
+  if (response.status !== 401) return response;
+  if (!(await refreshSession())) {
+    onSessionEnded();
+    return response;
+  }
+  return send();
+}
+
+// fetchAll follows next_offset through every page of a list endpoint.
+async function fetchAll<T>(path: string): Promise<T[]> {
+  const items: T[] = [];
+  const separator = path.includes('?') ? '&' : '?';
+  let offset: number | null = 0;
+  while (offset !== null) {
+    const response = await apiFetch(`${path}${separator}limit=200&offset=${offset}`);
+    if (!response.ok) {
+      throw new Error(`Failed to load ${path}`);
+    }
+    const page = await response.json();
+    items.push(...page.data);
+    offset = page.next_offset;
+  }
+  return items;
+}
+
 // ============================================================================
 // TYPES
 // ============================================================================
@@ -66,6 +144,14 @@ function AuthProvider({ children }: { children: ReactNode }) {
   const [user, setUser] = useState<User | null>(null);
   const [token, setToken] = useState<string | null>(localStorage.getItem('token'));
 
+  useEffect(() => {
+    onSessionEnded = () => {
+      setUser(null);
+      setToken(null);
+      clearSession();
+    };
+  }, []);
+
   useEffect(() => {
     if (token) {
       fetchUser();
@@ -74,9 +160,7 @@ function AuthProvider({ children }: { children: ReactNode }) {
 
   const fetchUser = async () => {
     try {
-      const response = await fetch(`${API_URL}/auth/me`, {
-        headers: { Authorization: `Bearer ${token}` }
-      });
+      const response = await apiFetch('/auth/me');
       if (response.ok) {
         const data = await response.json();
         setUser(data);
@@ -101,9 +185,9 @@ function AuthProvider({ children }: { children: ReactNode }) {
     }
 
     const data = await response.json();
+    storeSession(data.token, data.refresh_token);
     setToken(data.token);
     setUser(data.user);
-    localStorage.setItem('token', data.token);
   };
 
   const register = async (email: string, password: string) => {
@@ -118,15 +202,16 @@ function AuthProvider({ children }: { children: ReactNode }) {
     }
 
     const data = await response.json();
+    storeSession(data.token, data.refresh_token);
     setToken(data.token);
     setUser(data.user);
-    localStorage.setItem('token', data.token);
   };
 
   const logout = () => {
+    apiFetch('/auth/logout', { method: 'POST' }).catch(() => {});
     setUser(null);
     setToken(null);
-    localStorage.removeItem('token');
+    clearSession();
   };
 
   return (
@@ -258,7 +343,6 @@ function Navigation() {
 }
 
 function Dashboard() {
-  const { token } = useAuth();
   const [stats, setStats] = useState({ employees: 0, activeProjects: 0, pendingTasks: 0 });
   const [loading, setLoading] = useState(true);
 
@@ -269,19 +353,19 @@ function Dashboard() {
   const fetchStats = async () => {
     try {
       const [employeesRes, projectsRes, tasksRes] = await Promise.all([
-        fetch(`${API_URL}/employees`, { headers: { Authorization: `Bearer ${token}` } }),
-        fetch(`${API_URL}/projects`, { headers: { Authorization: `Bearer ${token}` } }),
-        fetch(`${API_URL}/tasks`, { headers: { Authorization: `Bearer ${token}` } })
+        apiFetch('/employees?limit=1'),
+        apiFetch('/projects?status=active&limit=1'),
+        apiFetch('/tasks?status=todo,in_progress&limit=1')
       ]);
 
-      const employees = employeesRes.ok ? await employeesRes.json() || [] : [];
-      const projects = projectsRes.ok ? await projectsRes.json() || [] : [];
-      const tasks = tasksRes.ok ? await tasksRes.json() || [] : [];
+      const employees = employeesRes.ok ? await employeesRes.json() : { total: 0 };
+      const projects = projectsRes.ok ? await projectsRes.json() : { total: 0 };
+      const tasks = tasksRes.ok ? await tasksRes.json() : { total: 0 };
 
       setStats({
-        employees: employees.length,
-        activeProjects: projects.filter((p: Project) => p.status === 'active').length,
-        pendingTasks: tasks.filter((t: Task) => t.status !== 'completed').length
+        employees: employees.total,
+        activeProjects: projects.total,
+        pendingTasks: tasks.total
       });
     } catch (error) {
       console.error('Failed to fetch stats:', error);
@@ -306,7 +390,6 @@ function Dashboard() {
 }
 
 function Employees() {
-  const { token } = useAuth();
   const [employees, setEmployees] = useState<Employee[]>([]);
   const [loading, setLoading] = useState(true);
 
@@ -316,13 +399,7 @@ function Employees() {
 
   const fetchEmployees = async () => {
     try {
-      const response = await fetch(`${API_URL}/employees`, {
-        headers: { Authorization: `Bearer ${token}` }
-      });
-      if (response.ok) {
-        const data = await response.json();
-        setEmployees(data || []);
-      }
+      setEmployees(await fetchAll<Employee>('/employees'));
     } catch (error) {
       console.error('Failed to fetch employees:', error);
     } finally {
@@ -334,10 +411,7 @@ function Employees() {
     if (!confirm('Are you sure you want to delete this employee?')) return;
 
     try {
-      const response = await fetch(`${API_URL}/employees/${id}`, {
-        method: 'DELETE',
-        headers: { Authorization: `Bearer ${token}` }
-      });
+      const response = await apiFetch(`/employees/${id}`, { method: 'DELETE' });
       if (response.ok) {
         fetchEmployees();
       }
@@ -391,7 +465,6 @@ function Employees() {
 }
 
 function Projects() {
-  const { token } = useAuth();
   const [projects, setProjects] = useState<Project[]>([]);
   const [loading, setLoading] = useState(true);
 
@@ -401,13 +474,7 @@ function Projects() {
 
   const fetchProjects = async () => {
     try {
-      const response = await fetch(`${API_URL}/projects`, {
-        headers: { Authorization: `Bearer ${token}` }
-      });
-      if (response.ok) {
-        const data = await response.json();
-        setProjects(data || []);
-      }
+      setProjects(await fetchAll<Project>('/projects'));
     } catch (error) {
       console.error('Failed to fetch projects:', error);
     } finally {
@@ -419,10 +486,7 @@ function Projects() {
     if (!confirm('Are you sure you want to delete this project?')) return;
 
     try {
-      const response = await fetch(`${API_URL}/projects/${id}`, {
-        method: 'DELETE',
-        headers: { Authorization: `Bearer ${token}` }
-      });
+      const response = await apiFetch(`/projects/${id}`, { method: 'DELETE' });
       if (response.ok) {
         fetchProjects();
       }
@@ -483,7 +547,6 @@ function Projects() {
 }
 
 function Tasks() {
-  const { token } = useAuth();
   const [tasks, setTasks] = useState<Task[]>([]);
   const [projects, setProjects] = useState<Project[]>([]);
   const [employees, setEmployees] = useState<Employee[]>([]);
@@ -495,15 +558,15 @@ function Tasks() {
 
   const fetchAllData = async () => {
     try {
-      const [tasksRes, projectsRes, employeesRes] = await Promise.all([
-        fetch(`${API_URL}/tasks`, { headers: { Authorization: `Bearer ${token}` } }),
-        fetch(`${API_URL}/projects`, { headers: { Authorization: `Bearer ${token}` } }),
-        fetch(`${API_URL}/employees`, { headers: { Authorization: `Bearer ${token}` } })
+      const [allTasks, allProjects, allEmployees] = await Promise.all([
+        fetchAll<Task>('/tasks'),
+        fetchAll<Project>('/projects'),
+        fetchAll<Employee>('/employees')
       ]);
 
-      if (tasksRes.ok) setTasks(await tasksRes.json() || []);
-      if (projectsRes.ok) setProjects(await projectsRes.json() || []);
-      if (employeesRes.ok) setEmployees(await employeesRes.json() || []);
+      setTasks(allTasks);
+      setProjects(allProjects);
+      setEmployees(allEmployees);
     } catch (error) {
       console.error('Failed to fetch data:', error);
     } finally {
@@ -515,10 +578,7 @@ function Tasks() {
     if (!confirm('Are you sure you want to delete this task?')) return;
 
     try {
-      const response = await fetch(`${API_URL}/tasks/${id}`, {
-        method: 'DELETE',
-        headers: { Authorization: `Bearer ${token}` }
-      });
+      const response = await apiFetch(`/tasks/${id}`, { method: 'DELETE' });
       if (response.ok) {
         fetchAllData();
       }